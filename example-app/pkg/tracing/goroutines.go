@@ -2,8 +2,8 @@ package tracing
 
 import (
 	"runtime"
-	// unsafe allows us to use go:linkname
-	_ "unsafe"
+	"runtime/hooks"
+	"unsafe"
 
 	"go.opentelemetry.io/otel/trace"
 )
@@ -14,39 +14,67 @@ const spanGoRoutines = false
 type goRoutineTraceData struct {
 	goRoutineID uint64        // The ID of the go routine
 	context     *TraceContext // The trace context of the go routine
+	root        *hooks.Root   // The root entered by the request handler, if this is its go routine
+	rootTraceID string        // The trace ID the root is registered under
+}
+
+func init() {
+	hooks.RegisterGoroutineHooks(hooks.GoroutineHooks{
+		Start: func(pc uintptr, goRoutineID uint64, parentTraceData unsafe.Pointer) unsafe.Pointer {
+			return unsafe.Pointer(goRoutineStart(pc, goRoutineID, (*goRoutineTraceData)(parentTraceData)))
+		},
+		Exit: func(goRoutineID uint64, traceData unsafe.Pointer) {
+			goRoutineExit(goRoutineID, (*goRoutineTraceData)(traceData))
+		},
+		Rename: func(goRoutineID uint64, traceData unsafe.Pointer, name string) {
+			goRoutineRename(goRoutineID, (*goRoutineTraceData)(traceData), name)
+		},
+	})
 }
 
-//go:linkname goRoutineStart runtime.tracingGStart
 func goRoutineStart(pc uintptr, goRoutinueID uint64, parentTraceData *goRoutineTraceData) *goRoutineTraceData {
 	if parentTraceData == nil {
 		return nil
 	}
 
 	if spanGoRoutines {
+		// Name the span after the goroutine's inherited name, if any,
+		// until it names itself; see goRoutineRename.
+		name := hooks.GoroutineName()
+		if name == "" {
+			name = callingFunc(pc)
+		}
 		return &goRoutineTraceData{
 			goRoutineID: goRoutinueID,
-			context:     startSpanForOtherGoRoutine(goRoutinueID, callingFunc(pc), nil, parentTraceData.context, trace.SpanKindInternal),
+			context:     startSpanForOtherGoRoutine(goRoutinueID, name, nil, parentTraceData.context, trace.SpanKindInternal),
 		}
 	} else {
 		return parentTraceData
 	}
 }
 
-//go:linkname goRoutineExit runtime.tracingGExit
 func goRoutineExit(goRoutineID uint64, traceData *goRoutineTraceData) {
 	if spanGoRoutines && traceData.context != nil {
 		endSpanForOtherGoRoutine(goRoutineID, nil)
 	}
 }
 
-//go:linkname goRoutineAttachData runtime.tracingAttachDataToG
-func goRoutineAttachData(data *goRoutineTraceData)
+func goRoutineRename(goRoutineID uint64, traceData *goRoutineTraceData, name string) {
+	// Only rename the goroutine's own span, not one it shares with its parent.
+	if spanGoRoutines && traceData.goRoutineID == goRoutineID {
+		renameSpanForOtherGoRoutine(goRoutineID, name)
+	}
+}
 
-//go:linkname goRoutineGetData runtime.tracingGetDataFromG
-func goRoutineGetData() *goRoutineTraceData
+// goRoutineAttachData attaches data to the current go routine, as the trace data the hooks are called with
+func goRoutineAttachData(data *goRoutineTraceData) {
+	hooks.SetTraceData(unsafe.Pointer(data))
+}
 
-//go:linkname goRoutineID runtime.getgoid
-func goRoutineID() uint64
+// goRoutineGetData returns the trace data attached to the current go routine, if any
+func goRoutineGetData() *goRoutineTraceData {
+	return (*goRoutineTraceData)(hooks.TraceData())
+}
 
 func callingFunc(pc uintptr) string {
 	cf := runtime.CallersFrames([]uintptr{pc})
@@ -6,14 +6,23 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptrace"
-	// unsafe allows us to use go:linkname
-	_ "unsafe"
+	"runtime/hooks"
+	"runtime/hooks/httphooks"
 
 	"go.opentelemetry.io/otel/semconv/v1.17.0/httpconv"
 	"go.opentelemetry.io/otel/trace"
 )
 
-//go:linkname handlerStart net/http.tracingHandlerStart
+func init() {
+	httphooks.RegisterHTTPHooks(httphooks.HTTPHooks{
+		HandlerStart:   handlerStart,
+		HandlerEnd:     handlerEnd,
+		StartRoundTrip: startRoundTrip,
+		EndRoundTrip:   endRoundTrip,
+		Labels:         handlerLabels,
+	})
+}
+
 func handlerStart(req *http.Request) {
 	// Sanity check we're not already tracing, this should never happen
 	// as the handlerEnd function should always be called before the next
@@ -23,7 +32,7 @@ func handlerStart(req *http.Request) {
 	}
 
 	// Start tracing the go routine
-	goRoutineAttachData(&goRoutineTraceData{goRoutineID: goRoutineID()})
+	goRoutineAttachData(&goRoutineTraceData{goRoutineID: hooks.GoroutineID()})
 
 	// Get the trace ID from the request header
 	parentTrace, _ := ParseTraceContext(req.Header.Get(traceContextHeader))
@@ -35,16 +44,33 @@ func handlerStart(req *http.Request) {
 		trace.SpanKindServer,
 		httpconv.ServerRequest("", req)...,
 	)
+
+	// Group the go routines working on the request, so they can be dumped
+	// by RootHandler
+	traceData := goRoutineGetData()
+	traceData.rootTraceID = fmt.Sprintf("%x", traceData.context.TraceID)
+	traceData.root = enterRoot(traceData.rootTraceID)
+}
+
+// handlerLabels returns the profiler labels of the goroutine serving req,
+// so CPU profiles can be broken down by route and looked up by trace
+func handlerLabels(req *http.Request) []string {
+	kv := []string{"http.route", req.Method + " " + req.URL.Path}
+	if traceData := goRoutineGetData(); traceData != nil && traceData.context != nil {
+		kv = append(kv, "trace_id", fmt.Sprintf("%x", traceData.context.TraceID))
+	}
+	return kv
 }
 
-//go:linkname handlerEnd net/http.tracingHandlerEnd
 func handlerEnd(didPanic bool) {
 	// Sanity check we're tracing, this should never happen
 	// as the handlerStart function should always be called before
 	// the request ends
-	if goRoutineGetData() == nil {
+	traceData := goRoutineGetData()
+	if traceData == nil {
 		panic("go routine has no tracing data")
 	}
+	leaveRoot(traceData.rootTraceID, traceData.root)
 
 	var err error
 	if didPanic {
@@ -54,7 +80,6 @@ func handlerEnd(didPanic bool) {
 	goRoutineAttachData(nil)
 }
 
-//go:linkname startRoundTrip net/http.tracingStartRoundTrip
 func startRoundTrip(req *http.Request) *http.Request {
 	traceData := goRoutineGetData()
 	if traceData == nil {
@@ -102,7 +127,6 @@ func startRoundTrip(req *http.Request) *http.Request {
 	return req.WithContext(ctxWithTracer)
 }
 
-//go:linkname endRoundTrip net/http.tracingEndRoundTrip
 func endRoundTrip(resp *http.Response, err error) {
 	traceData := goRoutineGetData()
 	if traceData == nil {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/rand"
 	"fmt"
+	"sync"
 
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/exporters/jaeger"
@@ -15,7 +16,12 @@ import (
 
 var (
 	tracer trace.Tracer
-	spans  = map[uint64][]spanStackEntry{}
+
+	// spans holds the stack of open spans of each go routine. The hooks
+	// use it from other go routines than its owner, such as the one
+	// starting or renaming a go routine, so it's guarded by spansMu
+	spansMu sync.Mutex
+	spans   = map[uint64][]spanStackEntry{}
 )
 
 type spanStackEntry struct {
@@ -90,10 +96,12 @@ func startSpanForOtherGoRoutine(goid uint64, name string, remoteParent *TraceCon
 		trace.WithAttributes(attrs...),
 	)
 
+	spansMu.Lock()
 	spans[goid] = append([]spanStackEntry{{
 		span:   span,
 		parent: localParent,
 	}}, spans[goid]...)
+	spansMu.Unlock()
 
 	// Attach the span to the current goroutine
 	spanCtx := span.SpanContext()
@@ -105,6 +113,23 @@ func startSpanForOtherGoRoutine(goid uint64, name string, remoteParent *TraceCon
 	return traceCtx
 }
 
+// renameSpanForOtherGoRoutine renames the current span of the given goroutine
+func renameSpanForOtherGoRoutine(goid uint64, name string) {
+	if span := currentSpan(goid); span != nil {
+		span.SetName(name)
+	}
+}
+
+// currentSpan returns the current span of the given goroutine, if any
+func currentSpan(goid uint64) trace.Span {
+	spansMu.Lock()
+	defer spansMu.Unlock()
+	if len(spans[goid]) > 0 {
+		return spans[goid][0].span
+	}
+	return nil
+}
+
 func recordEvent(name string) {
 	data := goRoutineGetData()
 	if data == nil {
@@ -112,9 +137,8 @@ func recordEvent(name string) {
 		return
 	}
 
-	if len(spans[data.goRoutineID]) > 0 {
-		entry := spans[data.goRoutineID][0]
-		entry.span.AddEvent(name)
+	if span := currentSpan(data.goRoutineID); span != nil {
+		span.AddEvent(name)
 	}
 }
 
@@ -133,7 +157,14 @@ func endSpan(err error, attrs ...attribute.KeyValue) {
 }
 
 func endSpanForOtherGoRoutine(goid uint64, err error, attrs ...attribute.KeyValue) *TraceContext {
+	spansMu.Lock()
 	entry := spans[goid][0]
+	if len(spans[goid]) == 1 {
+		delete(spans, goid)
+	} else {
+		spans[goid] = spans[goid][1:]
+	}
+	spansMu.Unlock()
 
 	if len(attrs) > 0 {
 		entry.span.SetAttributes(attrs...)
@@ -147,7 +178,5 @@ func endSpanForOtherGoRoutine(goid uint64, err error, attrs ...attribute.KeyValu
 		trace.WithStackTrace(true),
 	)
 
-	spans[goid] = spans[goid][1:]
-
 	return entry.parent
 }
@@ -0,0 +1,57 @@
+package tracing
+
+import (
+	"net/http"
+	"runtime/hooks"
+	"runtime/hooks/httphooks"
+	"sync"
+)
+
+// roots holds the root of each request being handled, by trace ID,
+// so its goroutines can be dumped if it hangs
+var (
+	rootsMu sync.Mutex
+	roots   = make(map[string]*hooks.Root)
+)
+
+// RootHandler returns an HTTP handler which dumps the goroutines working
+// on the request with the trace ID given by the "id" query parameter.
+//
+// Like the net/http/pprof handlers, it stops the world and exposes stacks,
+// so must only be served to operators, such as on a separate debug
+// listener bound to localhost:
+//
+//	go http.ListenAndServe("localhost:6060", tracing.RootHandler())
+func RootHandler() http.Handler {
+	return httphooks.RootHandler(lookupRoot)
+}
+
+func lookupRoot(traceID string) *hooks.Root {
+	rootsMu.Lock()
+	defer rootsMu.Unlock()
+	return roots[traceID]
+}
+
+// enterRoot makes the current go routine, and any it starts, part of a
+// new root for the request with the given trace ID
+func enterRoot(traceID string) *hooks.Root {
+	root := hooks.NewRoot()
+	root.Enter()
+
+	rootsMu.Lock()
+	roots[traceID] = root
+	rootsMu.Unlock()
+	return root
+}
+
+// leaveRoot removes the current go routine from root, entered by enterRoot
+// for the request with the given trace ID
+func leaveRoot(traceID string, root *hooks.Root) {
+	root.Leave()
+
+	rootsMu.Lock()
+	if roots[traceID] == root {
+		delete(roots, traceID)
+	}
+	rootsMu.Unlock()
+}
@@ -2,12 +2,25 @@ package tracing
 
 import (
 	"fmt"
-	_ "unsafe"
+	"runtime/hooks/sqlhooks"
 
 	"go.opentelemetry.io/otel/trace"
 )
 
-//go:linkname sqlQueryStart database/sql.tracingQueryStart
+func init() {
+	sqlhooks.RegisterSQLHooks(sqlhooks.SQLHooks{
+		QueryStart: sqlQueryStart,
+		QueryEnd:   sqlQueryEnd,
+		Labels:     sqlLabels,
+	})
+}
+
+// sqlLabels returns the profiler labels of the goroutine running a query
+// with the given operation, such as SELECT
+func sqlLabels(operation string) []string {
+	return []string{"sql.operation", operation}
+}
+
 func sqlQueryStart(query string) {
 	traceData := goRoutineGetData()
 	if traceData == nil {
@@ -22,7 +35,6 @@ func sqlQueryStart(query string) {
 	)
 }
 
-//go:linkname sqlQueryEnd database/sql.tracingQueryEnd
 func sqlQueryEnd(err error) {
 	traceData := goRoutineGetData()
 	if traceData == nil {
@@ -1,7 +1,34 @@
 package sql
 
+import (
+	_ "unsafe"
+)
+
+// tracingHooks holds the SQL hooks registered through the
+// runtime/hooks/sqlhooks package. A nil hook is a no-op.
+var tracingHooks struct {
+	queryStart func(query string)
+	queryEnd   func(err error)
+}
+
+// hooks_setSQLHooks is called by runtime/hooks/sqlhooks.RegisterSQLHooks.
+//
+//go:linkname hooks_setSQLHooks runtime/hooks/sqlhooks.setSQLHooks
+func hooks_setSQLHooks(queryStart func(string), queryEnd func(error)) {
+	tracingHooks.queryStart = queryStart
+	tracingHooks.queryEnd = queryEnd
+}
+
 // tracingQueryStart is called when a query starts
-func tracingQueryStart(query string)
+func tracingQueryStart(query string) {
+	if f := tracingHooks.queryStart; f != nil {
+		f(query)
+	}
+}
 
 // tracingQueryEnd is called when a query finishes
-func tracingQueryEnd(err error)
+func tracingQueryEnd(err error) {
+	if f := tracingHooks.queryEnd; f != nil {
+		f(err)
+	}
+}
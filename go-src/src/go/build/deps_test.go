@@ -125,6 +125,9 @@ var depsRules = `
 	MATH
 	< runtime/metrics;
 
+	RUNTIME
+	< runtime/gls;
+
 	MATH, unicode/utf8
 	< strconv;
 
@@ -516,6 +519,20 @@ var depsRules = `
 	< net/http/cgi
 	< net/http/fcgi;
 
+	# Runtime hooks
+	FMT
+	< runtime/hooks/internal/labels
+	< runtime/hooks;
+
+	runtime/hooks, runtime/trace
+	< runtime/hooks/internal/span;
+
+	runtime/hooks/internal/span, net/http
+	< runtime/hooks/httphooks;
+
+	runtime/hooks/internal/span, database/sql
+	< runtime/hooks/sqlhooks;
+
 	# Profiling
 	FMT, compress/gzip, encoding/binary, text/tabwriter
 	< runtime/pprof;
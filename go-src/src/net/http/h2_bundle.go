@@ -6042,10 +6042,11 @@ func (sc *http2serverConn) runHandler(rw *http2responseWriter, req *Request, han
 				buf = buf[:runtime.Stack(buf, false)]
 				sc.logf("http2: panic serving %v: %v\n%s", sc.conn.RemoteAddr(), e, buf)
 			}
+			tracingHandlerEnd(true)
 			return
 		}
 		rw.handlerDone()
-		tracingHandlerEnd(didPanic)
+		tracingHandlerEnd(false)
 	}()
 	tracingHandlerStart(req)
 	handler(rw, req)
@@ -1853,12 +1853,12 @@ func (c *conn) serve(ctx context.Context) {
 			buf := make([]byte, size)
 			buf = buf[:runtime.Stack(buf, false)]
 			c.server.logf("http: panic serving %v: %v\n%s", c.remoteAddr, err, buf)
-
-			// If we're here, then we panicked from inside the handler.
-			// and need to trigger the end of the tracing
-			if didPanic {
-				tracingHandlerEnd(true)
-			}
+		}
+		// If didPanic is still set, the handler panicked, possibly with
+		// ErrAbortHandler, or called runtime.Goexit, and the end of the
+		// request has yet to be traced.
+		if didPanic {
+			tracingHandlerEnd(true)
 		}
 		if inFlightResponse != nil {
 			inFlightResponse.cancelCtx()
@@ -2008,6 +2008,9 @@ func (c *conn) serve(ctx context.Context) {
 		inFlightResponse = nil
 		w.cancelCtx()
 		if c.hijacked() {
+			// Trace the end of the request, which the handler
+			// has taken the connection over from
+			tracingHandlerEnd(false)
 			return
 		}
 		w.finishRequest()
@@ -4,17 +4,61 @@ import (
 	_ "unsafe"
 )
 
+// tracingHooks holds the HTTP hooks registered through the
+// runtime/hooks/httphooks package. A nil hook is a no-op.
+var tracingHooks struct {
+	handlerStart   func(req *Request)
+	handlerEnd     func(didPanic bool)
+	startRoundTrip func(req *Request) *Request
+	endRoundTrip   func(resp *Response, err error)
+}
+
+// hooks_setHTTPHooks is called by runtime/hooks/httphooks.RegisterHTTPHooks.
+//
+//go:linkname hooks_setHTTPHooks runtime/hooks/httphooks.setHTTPHooks
+func hooks_setHTTPHooks(
+	handlerStart func(*Request),
+	handlerEnd func(bool),
+	startRoundTrip func(*Request) *Request,
+	endRoundTrip func(*Response, error),
+) {
+	tracingHooks.handlerStart = handlerStart
+	tracingHooks.handlerEnd = handlerEnd
+	tracingHooks.startRoundTrip = startRoundTrip
+	tracingHooks.endRoundTrip = endRoundTrip
+}
+
 // tracingHandlerStart is called when a HTTP request starts.
-func tracingHandlerStart(req *Request)
+func tracingHandlerStart(req *Request) {
+	if f := tracingHooks.handlerStart; f != nil {
+		f(req)
+	}
+}
 
 // tracingHandlerEnd is called when a HTTP request ends.
 //
 // If the handler panicked, didPanic will be true
 // otherwise it will be false.
-func tracingHandlerEnd(didPanic bool)
+func tracingHandlerEnd(didPanic bool) {
+	if f := tracingHooks.handlerEnd; f != nil {
+		f(didPanic)
+	}
+}
 
 // tracingStartRoundTrip is called when a HTTP request starts.
-func tracingStartRoundTrip(req *Request) *Request
+//
+// It returns the request to send, which may be a copy of req
+// carrying additional headers or context.
+func tracingStartRoundTrip(req *Request) *Request {
+	if f := tracingHooks.startRoundTrip; f != nil {
+		return f(req)
+	}
+	return req
+}
 
 // tracingEndRoundTrip is called when a HTTP request ends.
-func tracingEndRoundTrip(resp *Response, err error)
+func tracingEndRoundTrip(resp *Response, err error) {
+	if f := tracingHooks.endRoundTrip; f != nil {
+		f(resp, err)
+	}
+}
@@ -170,6 +170,9 @@ func cgocall(fn, arg unsafe.Pointer) int32 {
 	// reschedule us on to a different M.
 	mp.incgo = false
 	mp.ncgo--
+	if gp := mp.curg; gp.traceSyscallStart > 0 {
+		gp.traceInCgo = true
+	}
 
 	osPreemptExtExit(mp)
 
@@ -49,6 +49,11 @@ type hchan struct {
 	// (in particular, do not ready a G), as this can deadlock
 	// with stack shrinking.
 	lock mutex
+
+	// tracebuf holds the trace context sent with each element of buf.
+	// It is only allocated for buffered channels made while channel
+	// hooks are registered.
+	tracebuf []unsafe.Pointer
 }
 
 type waitq struct {
@@ -89,23 +94,33 @@ func makechan(t *chantype, size int) *hchan {
 	// buf points into the same allocation, elemtype is persistent.
 	// SudoG's are referenced from their owning thread so they can't be collected.
 	// TODO(dvyukov,rlh): Rethink when collector can move allocated objects.
+	// A traced channel's tracebuf is a pointer the GC must see, so its
+	// Hchan is always allocated with pointers.
+	traced := size > 0 && tracingHooks.chanSend != nil
 	var c *hchan
 	switch {
-	case mem == 0:
+	case mem == 0 && !traced:
 		// Queue or element size is zero.
 		c = (*hchan)(mallocgc(hchanSize, nil, true))
 		// Race detector uses this location for synchronization.
 		c.buf = c.raceaddr()
-	case elem.ptrdata == 0:
+	case elem.ptrdata == 0 && !traced:
 		// Elements do not contain pointers.
 		// Allocate hchan and buf in one call.
 		c = (*hchan)(mallocgc(hchanSize+mem, nil, true))
 		c.buf = add(unsafe.Pointer(c), hchanSize)
+	case mem == 0:
+		// Queue or element size is zero, traced.
+		c = new(hchan)
+		c.buf = c.raceaddr()
 	default:
-		// Elements contain pointers.
+		// Elements contain pointers, or traced.
 		c = new(hchan)
 		c.buf = mallocgc(mem, elem, true)
 	}
+	if traced {
+		c.tracebuf = make([]unsafe.Pointer, size)
+	}
 
 	c.elemsize = uint16(elem.size)
 	c.elemtype = elem
@@ -199,17 +214,20 @@ func chansend(c *hchan, ep unsafe.Pointer, block bool, callerpc uintptr) bool {
 		t0 = cputicks()
 	}
 
+	sent := tracingChanSend(c)
+
 	lock(&c.lock)
 
 	if c.closed != 0 {
 		unlock(&c.lock)
+		tracingChanDiscard(sent)
 		panic(plainError("send on closed channel"))
 	}
 
 	if sg := c.recvq.dequeue(); sg != nil {
 		// Found a waiting receiver. We pass the value we want to send
 		// directly to the receiver, bypassing the channel buffer (if any).
-		send(c, sg, ep, func() { unlock(&c.lock) }, 3)
+		send(c, sg, ep, sent, func() { unlock(&c.lock) }, 3)
 		return true
 	}
 
@@ -220,6 +238,9 @@ func chansend(c *hchan, ep unsafe.Pointer, block bool, callerpc uintptr) bool {
 			racenotify(c, c.sendx, nil)
 		}
 		typedmemmove(c.elemtype, qp, ep)
+		if c.tracebuf != nil {
+			c.tracebuf[c.sendx] = sent
+		}
 		c.sendx++
 		if c.sendx == c.dataqsiz {
 			c.sendx = 0
@@ -231,6 +252,7 @@ func chansend(c *hchan, ep unsafe.Pointer, block bool, callerpc uintptr) bool {
 
 	if !block {
 		unlock(&c.lock)
+		tracingChanDiscard(sent)
 		return false
 	}
 
@@ -244,6 +266,7 @@ func chansend(c *hchan, ep unsafe.Pointer, block bool, callerpc uintptr) bool {
 	// No stack splits between assigning elem and enqueuing mysg
 	// on gp.waiting where copystack can find it.
 	mysg.elem = ep
+	mysg.traceData = sent
 	mysg.waitlink = nil
 	mysg.g = gp
 	mysg.isSelect = false
@@ -275,11 +298,13 @@ func chansend(c *hchan, ep unsafe.Pointer, block bool, callerpc uintptr) bool {
 		blockevent(mysg.releasetime-t0, 2)
 	}
 	mysg.c = nil
+	mysg.traceData = nil
 	releaseSudog(mysg)
 	if closed {
 		if c.closed == 0 {
 			throw("chansend: spurious wakeup")
 		}
+		tracingChanDiscard(sent)
 		panic(plainError("send on closed channel"))
 	}
 	return true
@@ -288,10 +313,11 @@ func chansend(c *hchan, ep unsafe.Pointer, block bool, callerpc uintptr) bool {
 // send processes a send operation on an empty channel c.
 // The value ep sent by the sender is copied to the receiver sg.
 // The receiver is then woken up to go on its merry way.
+// sent is the sender's trace context, handed to the receiver with the value.
 // Channel c must be empty and locked.  send unlocks c with unlockf.
 // sg must already be dequeued from c.
 // ep must be non-nil and point to the heap or the caller's stack.
-func send(c *hchan, sg *sudog, ep unsafe.Pointer, unlockf func(), skip int) {
+func send(c *hchan, sg *sudog, ep, sent unsafe.Pointer, unlockf func(), skip int) {
 	if raceenabled {
 		if c.dataqsiz == 0 {
 			racesync(c, sg)
@@ -312,6 +338,7 @@ func send(c *hchan, sg *sudog, ep unsafe.Pointer, unlockf func(), skip int) {
 		sendDirect(c.elemtype, sg, ep)
 		sg.elem = nil
 	}
+	sg.traceData = sent
 	gp := sg.g
 	unlockf()
 	gp.param = unsafe.Pointer(sg)
@@ -529,7 +556,8 @@ func chanrecv(c *hchan, ep unsafe.Pointer, block bool) (selected, received bool)
 			// directly from sender. Otherwise, receive from head of queue
 			// and add sender's value to the tail of the queue (both map to
 			// the same buffer slot because the queue is full).
-			recv(c, sg, ep, func() { unlock(&c.lock) }, 3)
+			sent := recv(c, sg, ep, func() { unlock(&c.lock) }, 3)
+			tracingChanRecv(sent)
 			return true, true
 		}
 	}
@@ -544,12 +572,18 @@ func chanrecv(c *hchan, ep unsafe.Pointer, block bool) (selected, received bool)
 			typedmemmove(c.elemtype, ep, qp)
 		}
 		typedmemclr(c.elemtype, qp)
+		var sent unsafe.Pointer
+		if c.tracebuf != nil {
+			sent = c.tracebuf[c.recvx]
+			c.tracebuf[c.recvx] = nil
+		}
 		c.recvx++
 		if c.recvx == c.dataqsiz {
 			c.recvx = 0
 		}
 		c.qcount--
 		unlock(&c.lock)
+		tracingChanRecv(sent)
 		return true, true
 	}
 
@@ -592,9 +626,12 @@ func chanrecv(c *hchan, ep unsafe.Pointer, block bool) (selected, received bool)
 		blockevent(mysg.releasetime-t0, 2)
 	}
 	success := mysg.success
+	sent := mysg.traceData
 	gp.param = nil
 	mysg.c = nil
+	mysg.traceData = nil
 	releaseSudog(mysg)
+	tracingChanRecv(sent)
 	return true, success
 }
 
@@ -612,8 +649,10 @@ func chanrecv(c *hchan, ep unsafe.Pointer, block bool) (selected, received bool)
 // Channel c must be full and locked. recv unlocks c with unlockf.
 // sg must already be dequeued from c.
 // A non-nil ep must point to the heap or the caller's stack.
-func recv(c *hchan, sg *sudog, ep unsafe.Pointer, unlockf func(), skip int) {
+// recv returns the trace context sent with the value the receiver got.
+func recv(c *hchan, sg *sudog, ep unsafe.Pointer, unlockf func(), skip int) (sent unsafe.Pointer) {
 	if c.dataqsiz == 0 {
+		sent = sg.traceData
 		if raceenabled {
 			racesync(c, sg)
 		}
@@ -637,6 +676,10 @@ func recv(c *hchan, sg *sudog, ep unsafe.Pointer, unlockf func(), skip int) {
 		}
 		// copy data from sender to queue
 		typedmemmove(c.elemtype, qp, sg.elem)
+		if c.tracebuf != nil {
+			sent = c.tracebuf[c.recvx]
+			c.tracebuf[c.recvx] = sg.traceData
+		}
 		c.recvx++
 		if c.recvx == c.dataqsiz {
 			c.recvx = 0
@@ -644,6 +687,7 @@ func recv(c *hchan, sg *sudog, ep unsafe.Pointer, unlockf func(), skip int) {
 		c.sendx = c.recvx // c.sendx = (c.sendx+1) % c.dataqsiz
 	}
 	sg.elem = nil
+	sg.traceData = nil
 	gp := sg.g
 	unlockf()
 	gp.param = unsafe.Pointer(sg)
@@ -652,6 +696,7 @@ func recv(c *hchan, sg *sudog, ep unsafe.Pointer, unlockf func(), skip int) {
 		sg.releasetime = cputicks()
 	}
 	goready(gp, skip+1)
+	return sent
 }
 
 func chanparkcommit(gp *g, chanLock unsafe.Pointer) bool {
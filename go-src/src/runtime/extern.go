@@ -161,6 +161,14 @@ It is a comma-separated list of name=val pairs setting these named variables:
 	detailed multiline info every X milliseconds, describing state of the scheduler,
 	processors, threads and goroutines.
 
+	schedseed: setting schedseed=N, for a non-zero N, makes the scheduler's choice of
+	which runnable goroutine to run next, and the order in which select statements poll
+	their cases, random. With GOMAXPROCS=1, the choices are driven by a generator seeded
+	with N, so running a test again with the same seed reproduces the same interleaving
+	of its goroutines, as long as it is not disturbed by preemption, timers or I/O. With
+	more Ps, the choices are not reproducible. This is intended for reproducing
+	concurrency bugs in tests, not for production use.
+
 	schedtrace: setting schedtrace=X causes the scheduler to emit a single line to standard
 	error every X milliseconds, summarizing the scheduler state.
 
@@ -170,6 +178,13 @@ It is a comma-separated list of name=val pairs setting these named variables:
 	IDs will refer to the ID of the goroutine at the time of creation; it's possible for this
 	ID to be reused for another goroutine. Setting N to 0 will report no ancestry information.
 
+	tracinghooks: setting tracinghooks=1 causes the runtime to print a line to standard
+	error as each goroutine with trace data, as managed by the runtime/hooks package,
+	starts or exits, and as trace data is attached to or detached from a goroutine.
+	Setting tracinghooks=2 also prints a line for each call to the park, scheduler
+	and channel hooks, and for each HTTP and SQL span started or ended by the
+	runtime/hooks/httphooks and runtime/hooks/sqlhooks packages.
+
 	asyncpreemptoff: asyncpreemptoff=1 disables signal-based
 	asynchronous goroutine preemption. This makes some loops
 	non-preemptible for long periods, which may delay GC and
@@ -0,0 +1,188 @@
+package runtime
+
+import (
+	"runtime/internal/atomic"
+	"unsafe"
+)
+
+// maxGData is the number of per-goroutine data slots on each g.
+const maxGData = 8
+
+// Reserved per-goroutine data slots. The remaining slots are handed
+// out by hooks_newGoroutineSlot.
+const (
+	// gdataTrace holds the trace data of the library registered
+	// through runtime/hooks.RegisterGoroutineHooks.
+	gdataTrace = iota
+
+	// gdataGLS holds the goroutine-local storage of runtime/gls.
+	gdataGLS
+
+	gdataReserved
+)
+
+// gdataSlot holds the callbacks of an allocated per-goroutine data slot.
+type gdataSlot struct {
+	// inherit is called from newproc when the creating goroutine has data
+	// in this slot. It is passed the PC of the new goroutine's function,
+	// the new goroutine's ID and the parent's data, and returns the data
+	// for the new goroutine. If nil, new goroutines start with no data.
+	inherit func(pc uintptr, goRoutineID uint64, parentData unsafe.Pointer) unsafe.Pointer
+
+	// cleanup is called from goexit1 when the exiting goroutine has
+	// data in this slot. If nil, the data is simply dropped.
+	cleanup func(goRoutineID uint64, data unsafe.Pointer)
+}
+
+var (
+	gdataSlots [maxGData]gdataSlot
+
+	// gdataAllocated is the number of slots allocated
+	// by hooks_newGoroutineSlot.
+	gdataAllocated atomic.Int32
+)
+
+// gdataInherit runs the inherit callback of each of the creating
+// goroutine's occupied data slots, storing the result on newg, and gives
+// newg the creator's scheduling class. newg must be a user goroutine.
+//
+// newproc calls it on the creating goroutine's own stack, not the system
+// stack newproc1 runs on, as the callbacks are ordinary Go code which may
+// allocate and grow the stack: runtime/gls copies each value under its
+// Copy policy into a new map. For the same reason goexit1 calls
+// gdataCleanup before switching to the system stack.
+func gdataInherit(newg, callergp *g, pc uintptr) {
+	if callergp == callergp.m.g0 {
+		// A goroutine started from a timer function on the system
+		// stack, where the inherit callbacks can't safely run. It
+		// takes the timer's trace data unchanged; see runOneTimer.
+		newg.gdata[gdataTrace] = callergp.m.timerTraceData
+		newg.traceFromTimer = newg.gdata[gdataTrace] != nil
+		if debug.tracinghooks > 0 {
+			tracingDebugStart(newg, callergp)
+		}
+		return
+	}
+
+	if raceenabled {
+		raceacquire(unsafe.Pointer(&callergp.gdata))
+	}
+
+	newg.schedClass = callergp.schedClass
+
+	// While the callbacks run, CreationStack returns newg's stack
+	// rather than callergp's own. See hooks_creationStack.
+	prevNewg := callergp.traceNewg
+	callergp.traceNewg = newg
+	inherited := false
+	for i := range callergp.gdata {
+		if parent := callergp.gdata[i]; parent != nil {
+			if inherit := gdataSlots[i].inherit; inherit != nil {
+				newg.gdata[i] = inherit(pc, newg.goid, parent)
+				inherited = true
+			}
+		}
+	}
+	callergp.traceNewg = prevNewg
+
+	// A goroutine sharing its creator's trace data shares its label,
+	// saving a call to the label hook for each goroutine started.
+	if l := callergp.traceLabel; l != nil && l.traceData == newg.gdata[gdataTrace] {
+		newg.traceLabel = l
+	} else {
+		tracingUpdateLabel(newg)
+	}
+	if debug.tracinghooks > 0 {
+		tracingDebugStart(newg, callergp)
+	}
+
+	// newg's race context was created in newproc1, before the inherit
+	// hooks ran, so synchronize with newg's reads of its data, which
+	// all acquire &gp.gdata.
+	if raceenabled && inherited {
+		racerelease(unsafe.Pointer(&newg.gdata))
+	}
+}
+
+// gdataGet returns the data in the given slot of the current goroutine.
+func gdataGet(slot int) unsafe.Pointer {
+	gp := getg()
+	if raceenabled {
+		raceacquire(unsafe.Pointer(&gp.gdata))
+	}
+	return gp.gdata[slot]
+}
+
+// gdataCleanup runs the cleanup callback of each of the exiting
+// goroutine's occupied data slots, and clears them so the g can be reused.
+func gdataCleanup(gp *g) {
+	if raceenabled {
+		raceacquire(unsafe.Pointer(&gp.gdata))
+	}
+	if debug.tracinghooks > 0 && gp.gdata[gdataTrace] != nil {
+		tracingDebug(gp, "exit", gp.gdata[gdataTrace])
+	}
+
+	for i := range gp.gdata {
+		if data := gp.gdata[i]; data != nil {
+			// The exit hook is only called for trace data which the
+			// start hook returned or which was attached on gp, not for
+			// the trace data a goroutine started by a timer shares with
+			// the goroutine which set it.
+			if cleanup := gdataSlots[i].cleanup; cleanup != nil && !(i == gdataTrace && gp.traceFromTimer) {
+				cleanup(gp.goid, data)
+			}
+			gp.gdata[i] = nil
+		}
+	}
+	gp.traceLabel = nil
+	gp.traceFromTimer = false
+}
+
+// hooks_newGoroutineSlot is called by runtime/hooks.NewGoroutineSlot.
+// It returns the index of the allocated slot, or -1 if all slots are in use.
+//
+//go:linkname hooks_newGoroutineSlot runtime/hooks.newGoroutineSlot
+func hooks_newGoroutineSlot(inherit func(uintptr, uint64, unsafe.Pointer) unsafe.Pointer, cleanup func(uint64, unsafe.Pointer)) int {
+	i := gdataReserved + gdataAllocated.Add(1) - 1
+	if i >= maxGData {
+		return -1
+	}
+
+	// The slot is not visible to newproc or goexit1 until a goroutine
+	// stores data in it, which can only happen after we've returned.
+	gdataSlots[i].inherit = inherit
+	gdataSlots[i].cleanup = cleanup
+	return int(i)
+}
+
+// hooks_getGoroutineSlot is called by runtime/hooks.GoroutineSlot.Get.
+//
+//go:linkname hooks_getGoroutineSlot runtime/hooks.getGoroutineSlot
+func hooks_getGoroutineSlot(slot int) unsafe.Pointer {
+	return gdataGet(slot)
+}
+
+// hooks_setGoroutineSlot is called by runtime/hooks.GoroutineSlot.Set.
+//
+//go:linkname hooks_setGoroutineSlot runtime/hooks.setGoroutineSlot
+func hooks_setGoroutineSlot(slot int, data unsafe.Pointer) {
+	getg().gdata[slot] = data
+}
+
+// gls_registerSlot is called by runtime/gls as it is initialized.
+//
+//go:linkname gls_registerSlot runtime/gls.registerSlot
+func gls_registerSlot(inherit func(uintptr, uint64, unsafe.Pointer) unsafe.Pointer) {
+	gdataSlots[gdataGLS].inherit = inherit
+}
+
+//go:linkname gls_getStore runtime/gls.getStore
+func gls_getStore() unsafe.Pointer {
+	return gdataGet(gdataGLS)
+}
+
+//go:linkname gls_setStore runtime/gls.setStore
+func gls_setStore(s unsafe.Pointer) {
+	getg().gdata[gdataGLS] = s
+}
@@ -0,0 +1,189 @@
+// Package gls provides typed goroutine-local storage.
+//
+// A value stored under a Key is only visible to the goroutine which stored
+// it, plus any goroutines it starts afterwards which inherit the key under the
+// key's InheritPolicy. This makes it possible to carry request-scoped values,
+// such as a request ID for logging, into code which has no context.Context
+// to hand.
+//
+//	var requestID = gls.NewKey[string](gls.Copy)
+//
+//	func handle(w http.ResponseWriter, req *http.Request) {
+//		requestID.Set(req.Header.Get("X-Request-Id"))
+//		defer requestID.Delete()
+//		...
+//	}
+//
+// All keys share a single per-goroutine data slot in the runtime, so the
+// number of keys is not limited.
+package gls
+
+import (
+	"sync/atomic"
+	"unsafe"
+)
+
+// An InheritPolicy controls what a new goroutine inherits
+// for a Key from the goroutine which started it.
+type InheritPolicy int
+
+const (
+	// Copy gives the new goroutine its own copy of the parent's value.
+	// Later calls to Set in either goroutine are not seen by the other.
+	Copy InheritPolicy = iota
+
+	// Share gives the new goroutine the same storage as the parent.
+	// A call to Set in either goroutine is seen by both, as well as any
+	// other goroutines sharing the value. Delete only affects the
+	// goroutine which calls it.
+	Share
+
+	// None does not pass the value on, so the new
+	// goroutine starts with no value for the key.
+	None
+)
+
+// A Key identifies a goroutine-local value of type T.
+// Keys must be created with NewKey; the zero Key is not valid.
+type Key[T any] struct {
+	k *key
+}
+
+// key is the type independent part of a Key.
+type key struct {
+	policy InheritPolicy
+}
+
+// NewKey returns a new Key which passes its values to
+// new goroutines according to the given policy.
+func NewKey[T any](policy InheritPolicy) Key[T] {
+	if policy < Copy || policy > None {
+		panic("gls: invalid InheritPolicy")
+	}
+	return Key[T]{k: &key{policy: policy}}
+}
+
+// Get returns the value stored under k by the current goroutine
+// and whether a value was present.
+func (k Key[T]) Get() (v T, ok bool) {
+	c := current().find(k.k)
+	if c == nil {
+		return v, false
+	}
+	// A nil interface value is stored as a nil any,
+	// so the result of the assertion is ignored.
+	v, _ = (*c.v.Load()).(T)
+	return v, true
+}
+
+// Set stores v under k for the current goroutine.
+func (k Key[T]) Set(v T) {
+	s := current()
+	if s == nil {
+		s = new(store)
+		setStore(unsafe.Pointer(s))
+	}
+
+	c := s.find(k.k)
+	if c == nil {
+		c = new(cell)
+		s.entries = append(s.entries, entry{key: k.k, cell: c})
+	}
+	var boxed any = v
+	c.v.Store(&boxed)
+}
+
+// Delete removes the value stored under k for the current goroutine.
+func (k Key[T]) Delete() {
+	s := current()
+	if s == nil {
+		return
+	}
+	for i, e := range s.entries {
+		if e.key == k.k {
+			last := len(s.entries) - 1
+			s.entries[i] = s.entries[last]
+			s.entries[last] = entry{}
+			s.entries = s.entries[:last]
+			break
+		}
+	}
+	if len(s.entries) == 0 {
+		setStore(nil)
+	}
+}
+
+// store holds the goroutine-local values of a single goroutine.
+//
+// Only the owning goroutine reads or modifies entries,
+// including through inherit as it starts a goroutine.
+type store struct {
+	entries []entry
+}
+
+type entry struct {
+	key  *key
+	cell *cell
+}
+
+// cell holds a value. Under the Share policy a cell is referenced by
+// several goroutines' stores, so the value is updated atomically.
+type cell struct {
+	v atomic.Pointer[any]
+}
+
+// current returns the store of the current goroutine, or nil if it has none.
+func current() *store {
+	return (*store)(getStore())
+}
+
+func (s *store) find(k *key) *cell {
+	if s == nil {
+		return nil
+	}
+	if k == nil {
+		panic("gls: use of zero Key")
+	}
+	for _, e := range s.entries {
+		if e.key == k {
+			return e.cell
+		}
+	}
+	return nil
+}
+
+// inherit is called by the runtime on the parent goroutine as it starts a
+// goroutine, if the parent has a store, and returns the new goroutine's store.
+func inherit(pc uintptr, goRoutineID uint64, parent unsafe.Pointer) unsafe.Pointer {
+	p := (*store)(parent)
+
+	var entries []entry
+	for _, e := range p.entries {
+		switch e.key.policy {
+		case Copy:
+			c := new(cell)
+			c.v.Store(e.cell.v.Load())
+			entries = append(entries, entry{key: e.key, cell: c})
+		case Share:
+			entries = append(entries, e)
+		}
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+	return unsafe.Pointer(&store{entries: entries})
+}
+
+func init() {
+	registerSlot(inherit)
+}
+
+// registerSlot is implemented in the runtime package. It allocates the
+// per-goroutine data slot used to hold each goroutine's store.
+func registerSlot(inherit func(uintptr, uint64, unsafe.Pointer) unsafe.Pointer)
+
+// getStore is implemented in the runtime package.
+func getStore() unsafe.Pointer
+
+// setStore is implemented in the runtime package.
+func setStore(s unsafe.Pointer)
@@ -0,0 +1,146 @@
+package gls_test
+
+import (
+	"runtime/gls"
+	"sync"
+	"testing"
+)
+
+func TestGetSetDelete(t *testing.T) {
+	k := gls.NewKey[string](gls.Copy)
+
+	if v, ok := k.Get(); ok {
+		t.Fatalf("Get before Set = %q, true; want false", v)
+	}
+
+	k.Set("a")
+	if v, ok := k.Get(); !ok || v != "a" {
+		t.Errorf("Get = %q, %v; want %q, true", v, ok, "a")
+	}
+
+	k.Set("b")
+	if v, ok := k.Get(); !ok || v != "b" {
+		t.Errorf("Get after second Set = %q, %v; want %q, true", v, ok, "b")
+	}
+
+	k.Delete()
+	if v, ok := k.Get(); ok {
+		t.Errorf("Get after Delete = %q, true; want false", v)
+	}
+}
+
+func TestKeysAreIndependent(t *testing.T) {
+	a := gls.NewKey[int](gls.Copy)
+	b := gls.NewKey[int](gls.Copy)
+	defer a.Delete()
+	defer b.Delete()
+
+	a.Set(1)
+	b.Set(2)
+	if v, _ := a.Get(); v != 1 {
+		t.Errorf("a.Get() = %d; want 1", v)
+	}
+	if v, _ := b.Get(); v != 2 {
+		t.Errorf("b.Get() = %d; want 2", v)
+	}
+
+	a.Delete()
+	if v, ok := b.Get(); !ok || v != 2 {
+		t.Errorf("b.Get() after a.Delete() = %d, %v; want 2, true", v, ok)
+	}
+}
+
+func TestNilInterfaceValue(t *testing.T) {
+	k := gls.NewKey[error](gls.Copy)
+	defer k.Delete()
+
+	k.Set(nil)
+	if v, ok := k.Get(); !ok || v != nil {
+		t.Errorf("Get = %v, %v; want nil, true", v, ok)
+	}
+}
+
+func TestNotVisibleToOtherGoroutines(t *testing.T) {
+	k := gls.NewKey[string](gls.Share)
+
+	set := make(chan struct{})
+	got := make(chan bool)
+	go func() {
+		<-set
+		_, ok := k.Get()
+		got <- ok
+	}()
+
+	k.Set("a")
+	defer k.Delete()
+	close(set)
+	if <-got {
+		t.Error("goroutine started before Set sees the value")
+	}
+}
+
+func TestInheritPolicies(t *testing.T) {
+	copied := gls.NewKey[string](gls.Copy)
+	shared := gls.NewKey[string](gls.Share)
+	none := gls.NewKey[string](gls.None)
+	defer copied.Delete()
+	defer shared.Delete()
+	defer none.Delete()
+
+	copied.Set("parent")
+	shared.Set("parent")
+	none.Set("parent")
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		if v, ok := copied.Get(); !ok || v != "parent" {
+			t.Errorf("copied.Get() in child = %q, %v; want %q, true", v, ok, "parent")
+		}
+		if v, ok := shared.Get(); !ok || v != "parent" {
+			t.Errorf("shared.Get() in child = %q, %v; want %q, true", v, ok, "parent")
+		}
+		if v, ok := none.Get(); ok {
+			t.Errorf("none.Get() in child = %q, true; want false", v)
+		}
+
+		copied.Set("child")
+		shared.Set("child")
+	}()
+	wg.Wait()
+
+	if v, _ := copied.Get(); v != "parent" {
+		t.Errorf("copied.Get() after child Set = %q; want %q", v, "parent")
+	}
+	if v, _ := shared.Get(); v != "child" {
+		t.Errorf("shared.Get() after child Set = %q; want %q", v, "child")
+	}
+}
+
+func TestInheritedThroughGenerations(t *testing.T) {
+	k := gls.NewKey[int](gls.Copy)
+	k.Set(42)
+	defer k.Delete()
+
+	got := make(chan int)
+	go func() {
+		go func() {
+			v, _ := k.Get()
+			got <- v
+		}()
+	}()
+	if v := <-got; v != 42 {
+		t.Errorf("grandchild Get() = %d; want 42", v)
+	}
+}
+
+func TestInvalidPolicyPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("NewKey with an invalid policy did not panic")
+		}
+	}()
+	gls.NewKey[int](gls.InheritPolicy(42))
+}
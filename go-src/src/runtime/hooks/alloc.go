@@ -0,0 +1,44 @@
+package hooks
+
+import (
+	"unsafe"
+)
+
+// AllocHooks are called by the memory allocator as goroutines with trace
+// data allocate, allowing the heap memory allocated on behalf of a request
+// to be attributed to its trace data. Only memory allocated by goroutines
+// with trace data is counted, and counting only begins once the hooks are
+// registered with a positive Rate.
+type AllocHooks struct {
+	// Rate is the average number of bytes a goroutine allocates
+	// between sampled allocations, as with runtime.MemProfileRate.
+	// A higher rate makes allocation cheaper, at the cost of delaying
+	// the reports made to Alloc.
+	Rate int
+
+	// Alloc is called on a goroutine as one of its allocations is
+	// sampled, and also before its trace data changes or it exits,
+	// with the number of bytes and heap objects it allocated since
+	// Alloc was last called for it. Each allocation is counted at the
+	// size the allocator rounded it up to, so the bytes are exact, but
+	// the number of objects is estimated from the sizes of the
+	// allocations sampled, as in the memory profile.
+	//
+	// Alloc is called at the end of the memory allocator, but never
+	// while the runtime holds locks of its own: counts due to be
+	// reported then are reported at the next sampled allocation
+	// instead. It may be called while the goroutine holds locks of the
+	// program's, such as a sync.Mutex, so it must not block, and should
+	// not allocate memory itself. Any memory it does allocate is
+	// counted towards the next call.
+	Alloc func(traceData unsafe.Pointer, bytes, objects uintptr)
+}
+
+// RegisterAllocHooks registers the hooks called by the memory allocator
+// as goroutines with trace data allocate.
+func RegisterAllocHooks(h AllocHooks) {
+	setAllocHooks(h.Rate, h.Alloc)
+}
+
+// setAllocHooks is implemented in the runtime package.
+func setAllocHooks(rate int, alloc func(unsafe.Pointer, uintptr, uintptr))
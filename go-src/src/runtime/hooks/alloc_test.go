@@ -0,0 +1,53 @@
+package hooks
+
+import (
+	"testing"
+	"unsafe"
+)
+
+// The counters of the alloc hook's recorder.
+const (
+	allocBytes = iota
+	allocObjects
+	allocCalls
+)
+
+var allocSink []byte
+
+func TestAllocHooks(t *testing.T) {
+	data := unsafe.Pointer(new(int))
+	r := recordHooks(t, data, func(r *recorder) {
+		RegisterAllocHooks(AllocHooks{Rate: 4096, Alloc: func(traceData unsafe.Pointer, bytes, objects uintptr) {
+			if r.traced(traceData) {
+				r.add(allocBytes, int64(bytes))
+				r.add(allocObjects, int64(objects))
+				r.add(allocCalls, 1)
+			}
+		}})
+	}, func() { RegisterAllocHooks(AllocHooks{}) })
+
+	const n, size = 1000, 1024
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		SetTraceData(data)
+		for i := 0; i < n; i++ {
+			allocSink = make([]byte, size)
+		}
+		// Changing the trace data reports the remaining allocations.
+		SetTraceData(nil)
+	}()
+	<-done
+
+	if got := r.count(allocBytes); got < n*size {
+		t.Errorf("Alloc reported %d bytes; want at least %d", got, n*size)
+	}
+	// The objects are estimated from the sampled allocations, and Alloc
+	// is called once per sample, about every 4096 bytes.
+	if got := r.count(allocObjects); got < n/2 || got > 2*n {
+		t.Errorf("Alloc reported %d objects; want about %d", got, n)
+	}
+	if got, max := r.count(allocCalls), int64(2*n*size/4096+2); got > max {
+		t.Errorf("Alloc called %d times; want at most %d", got, max)
+	}
+}
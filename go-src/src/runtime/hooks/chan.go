@@ -0,0 +1,46 @@
+package hooks
+
+import (
+	"unsafe"
+)
+
+// ChanHooks are called by the runtime as values are sent and received
+// on channels, allowing a goroutine's trace data to follow the work it
+// hands to other goroutines, such as a pool of long-lived workers.
+//
+// The hooks run on the sending and receiving goroutines, but must not
+// themselves operate on channels or block.
+type ChanHooks struct {
+	// Send is called on a goroutine with trace data as it is about to
+	// send on a channel, including in a select statement. The value it
+	// returns is carried with the element sent, and nil carries nothing.
+	// Send is not called for sends on buffered channels made before the
+	// hooks were registered, which can't carry a value.
+	Send func(traceData unsafe.Pointer) unsafe.Pointer
+
+	// Recv is called on a goroutine which has received an element
+	// carrying a non-nil value from Send, with its own trace data, which
+	// may be nil. Recv returns the goroutine's new trace data, such as
+	// a span linked to the sender's, or the existing trace data unchanged.
+	Recv func(traceData, sent unsafe.Pointer) unsafe.Pointer
+
+	// Discard is called on the sending goroutine with a non-nil value
+	// returned by Send which no element carries after all, as Send runs
+	// before the send is known to succeed: the channel was closed, a
+	// send in a select statement with a default case found no receiver
+	// or buffer space, or the select picked another case. Each value
+	// returned by Send is passed to either Recv or Discard once, unless
+	// it is left in the buffer of a channel which becomes unreachable.
+	Discard func(sent unsafe.Pointer)
+}
+
+// RegisterChanHooks registers the hooks called by the runtime as values
+// are sent and received on channels. Only values sent on channels made
+// after the hooks are registered carry context through a channel buffer,
+// so the hooks should be registered from an init function.
+func RegisterChanHooks(h ChanHooks) {
+	setChanHooks(h.Send, h.Recv, h.Discard)
+}
+
+// setChanHooks is implemented in the runtime package.
+func setChanHooks(send func(unsafe.Pointer) unsafe.Pointer, recv func(unsafe.Pointer, unsafe.Pointer) unsafe.Pointer, discard func(unsafe.Pointer))
@@ -0,0 +1,140 @@
+package hooks
+
+import (
+	"testing"
+	"unsafe"
+)
+
+// chanContext is the context sent through channels in TestChanHooks.
+type chanContext struct {
+	from unsafe.Pointer
+}
+
+func TestChanHooks(t *testing.T) {
+	RegisterChanHooks(ChanHooks{
+		Send: func(traceData unsafe.Pointer) unsafe.Pointer {
+			return unsafe.Pointer(&chanContext{from: traceData})
+		},
+		Recv: func(traceData, sent unsafe.Pointer) unsafe.Pointer {
+			if traceData != nil {
+				t.Errorf("Recv called with trace data %p; want nil", traceData)
+			}
+			return (*chanContext)(sent).from
+		},
+	})
+	defer RegisterChanHooks(ChanHooks{})
+
+	tests := []struct {
+		name string
+		size int
+		recv func(ch chan int) bool
+	}{
+		{"unbuffered", 0, func(ch chan int) bool { _, ok := <-ch; return ok }},
+		{"buffered", 1, func(ch chan int) bool { _, ok := <-ch; return ok }},
+		{"select", 0, func(ch chan int) bool {
+			never := make(chan int)
+			select {
+			case <-ch:
+				return true
+			case <-never:
+				return false
+			}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := new(int)
+			ch := make(chan int, tt.size)
+			got := make(chan unsafe.Pointer, 1)
+			go func() {
+				if !tt.recv(ch) {
+					t.Error("receive failed")
+				}
+				p := TraceData()
+				SetTraceData(nil)
+				got <- p
+			}()
+
+			SetTraceData(unsafe.Pointer(data))
+			ch <- 1
+			SetTraceData(nil)
+
+			if p := <-got; p != unsafe.Pointer(data) {
+				t.Errorf("receiver has trace data %p; want %p", p, data)
+			}
+		})
+	}
+}
+
+func TestChanHooksDiscard(t *testing.T) {
+	old := make(chan int, 1)
+	var sent, discarded []unsafe.Pointer
+	RegisterChanHooks(ChanHooks{
+		Send: func(traceData unsafe.Pointer) unsafe.Pointer {
+			p := unsafe.Pointer(new(int))
+			sent = append(sent, p)
+			return p
+		},
+		Recv: func(traceData, sent unsafe.Pointer) unsafe.Pointer {
+			return traceData
+		},
+		Discard: func(p unsafe.Pointer) {
+			discarded = append(discarded, p)
+		},
+	})
+	defer RegisterChanHooks(ChanHooks{})
+
+	// Each test prepares the channels without trace data, and returns the
+	// traced send.
+	tests := []struct {
+		name            string
+		prepare         func() func()
+		sends, discards int
+	}{
+		{"closed", func() func() {
+			ch := make(chan int)
+			close(ch)
+			return func() {
+				defer func() { recover() }()
+				ch <- 1
+			}
+		}, 1, 1},
+		{"select recv", func() func() {
+			ch := make(chan int)
+			ready := make(chan int, 1)
+			ready <- 1
+			return func() {
+				select {
+				case ch <- 1:
+				case <-ready:
+				}
+			}
+		}, 1, 1},
+		{"buffered", func() func() {
+			ch := make(chan int, 1)
+			return func() { ch <- 1 }
+		}, 1, 0},
+		{"made before hooks", func() func() {
+			return func() { old <- 1 }
+		}, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			send := tt.prepare()
+			sent, discarded = nil, nil
+			SetTraceData(unsafe.Pointer(new(int)))
+			send()
+			SetTraceData(nil)
+
+			if len(sent) != tt.sends {
+				t.Fatalf("Send called %d times; want %d", len(sent), tt.sends)
+			}
+			if len(discarded) != tt.discards {
+				t.Fatalf("Discard called %d times; want %d", len(discarded), tt.discards)
+			}
+			if tt.discards > 0 && discarded[0] != sent[0] {
+				t.Errorf("Discard called with %p; want %p from Send", discarded[0], sent[0])
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package hooks
+
+import (
+	"unsafe"
+)
+
+// CrashHooks are called by the runtime as the program crashes, giving a
+// tracing library a last chance to record what was in flight, such as
+// by writing the trace data of every goroutine, as found through
+// EachGoroutineTraceData, to a local file.
+type CrashHooks struct {
+	// Crash is called once, on the crashing goroutine, after the panic
+	// value or fatal error is printed, but before the goroutine stacks
+	// are printed and the program exits. It is passed the goroutine's ID
+	// and trace data, which may be nil, and either the value of the
+	// unrecovered panic or the message of the fatal error, such as
+	// "concurrent map writes", which caused the crash.
+	//
+	// Crash is only called on a best-effort basis. Other goroutines
+	// keep running while it runs, and it is not called at all if the
+	// program crashes where it is unsafe to run ordinary Go code, nor
+	// for fatal errors within the runtime itself. If Crash panics, the
+	// program crashes with that panic instead, without calling Crash
+	// again.
+	//
+	// Crash is also called as the runtime finds every goroutine blocked,
+	// with the fatal error "all goroutines are asleep - deadlock!". It
+	// then runs on a goroutine of the runtime's own, with nil trace data;
+	// the trace data of the blocked goroutines can be found through
+	// EachGoroutineTraceData. If Crash itself blocks forever, the program
+	// crashes without waiting for it.
+	Crash func(goRoutineID uint64, traceData unsafe.Pointer, panicValue any, fatalError string)
+}
+
+// RegisterCrashHooks registers the hooks called by the runtime
+// as the program crashes.
+func RegisterCrashHooks(h CrashHooks) {
+	setCrashHooks(h.Crash)
+}
+
+// setCrashHooks is implemented in the runtime package.
+func setCrashHooks(crash func(uint64, unsafe.Pointer, any, string))
@@ -0,0 +1,39 @@
+package hooks
+
+import (
+	"unsafe"
+)
+
+// GCHooks are called by the garbage collector, allowing the latency it
+// adds to requests to be attributed to their trace data: the pauses in
+// which it stops every goroutine, and the mark assists in which it makes
+// a goroutine which is allocating quickly help with marking.
+//
+// Both hooks are passed the times the pause or assist started and ended,
+// as monotonic clock readings in nanoseconds. Assist is called from
+// within the garbage collector, so it must not block, and should not
+// allocate memory.
+type GCHooks struct {
+	// Pause is called after each stop-the-world phase of a garbage
+	// collection, including those in which the collector finds more
+	// marking to do and restarts the world to do it. There are
+	// normally two such phases in each collection, which last well
+	// under a millisecond. The phases are queued for a goroutine of
+	// the runtime's own, which calls Pause for each shortly after it
+	// ends, so Pause may block or allocate memory, though while it
+	// does further phases are queued, and dropped if too many are.
+	Pause func(start, end int64)
+
+	// Assist is called on a goroutine with trace data which had to
+	// assist the garbage collector before it could allocate, once it is
+	// done, including any time it spent waiting for credit.
+	Assist func(traceData unsafe.Pointer, start, end int64)
+}
+
+// RegisterGCHooks registers the hooks called by the garbage collector.
+func RegisterGCHooks(h GCHooks) {
+	setGCHooks(h.Pause, h.Assist)
+}
+
+// setGCHooks is implemented in the runtime package.
+func setGCHooks(pause func(int64, int64), assist func(unsafe.Pointer, int64, int64))
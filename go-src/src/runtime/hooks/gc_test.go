@@ -0,0 +1,72 @@
+package hooks
+
+import (
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"unsafe"
+)
+
+// The counters of the GC hooks' recorder.
+const (
+	gcPauses = iota
+	gcAssists
+	gcAssistOthers
+)
+
+var gcSink [][]byte
+
+func TestGCHooks(t *testing.T) {
+	data := unsafe.Pointer(new(int))
+	var pauseGoID atomic.Uint64
+	r := recordHooks(t, data, func(r *recorder) {
+		RegisterGCHooks(GCHooks{
+			Pause: func(start, end int64) {
+				pauseGoID.Store(GoroutineID())
+				r.add(gcPauses, 1)
+				r.times(start, end)
+			},
+			Assist: func(traceData unsafe.Pointer, start, end int64) {
+				if !r.traced(traceData) {
+					r.add(gcAssistOthers, 1)
+					return
+				}
+				r.add(gcAssists, 1)
+				r.times(start, end)
+			},
+		})
+	}, func() { RegisterGCHooks(GCHooks{}) })
+
+	// The pause hook is called asynchronously, so wait for it.
+	runtime.GC()
+	for i := 0; i < 1e6 && r.count(gcPauses) < 2; i++ {
+		runtime.Gosched()
+	}
+	if n := r.count(gcPauses); n < 2 {
+		t.Errorf("pause hook called %d times after runtime.GC; want at least 2", n)
+	}
+	if id := pauseGoID.Load(); id == GoroutineID() {
+		t.Errorf("pause hook called on goroutine %d, which ran runtime.GC", id)
+	}
+
+	// Allocate quickly, while the garbage collector runs, until this
+	// goroutine has to assist it.
+	SetTraceData(data)
+	defer SetTraceData(nil)
+	for i := 0; i < 1e6 && r.count(gcAssists) == 0; i++ {
+		gcSink = append(gcSink, make([]byte, 1024))
+		if len(gcSink) > 1e4 {
+			gcSink = nil
+		}
+	}
+	gcSink = nil
+	if r.count(gcAssists) == 0 {
+		t.Log("goroutine never assisted the garbage collector")
+	}
+	if n := r.count(gcAssistOthers); n != 0 {
+		t.Errorf("assist hook called %d times with the wrong trace data", n)
+	}
+	if n := r.badTimes.Load(); n != 0 {
+		t.Errorf("GC hooks called with bad times %d times", n)
+	}
+}
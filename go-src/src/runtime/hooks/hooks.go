@@ -0,0 +1,181 @@
+// Package hooks lets a library register the functions which the forked
+// runtime and standard library call at interesting points in a program's
+// life, such as a goroutine starting or an HTTP request being served.
+//
+// Every hook defaults to a no-op, so programs which never import this
+// package build and run exactly as they would with an unmodified toolchain.
+// Registering a nil hook restores the no-op default for that hook.
+//
+// The hooks called by the net/http and database/sql packages are
+// registered through the runtime/hooks/httphooks and runtime/hooks/sqlhooks
+// packages, so that only programs using them link those packages.
+//
+// The Register functions are not synchronised with the hooks being invoked,
+// so they must be called from an init function, before any goroutine which
+// could trigger a hook is started. Calling a Register function again replaces
+// every hook it covers.
+//
+// Setting GODEBUG=tracinghooks=1 prints the goroutine starts and exits and
+// the trace data attachments seen by the runtime to standard error, and
+// GODEBUG=tracinghooks=2 also prints the park, scheduler and channel hooks
+// called, including each channel send and receive carrying context, and
+// the HTTP and SQL spans started and ended. See the runtime package's
+// GODEBUG documentation.
+package hooks
+
+import (
+	"runtime/hooks/internal/labels"
+	"unsafe"
+)
+
+// GoroutineHooks are called by the runtime as goroutines start and exit.
+//
+// Trace data is an opaque pointer owned by the library registering the hooks.
+// It lives in a per-goroutine data slot reserved for the trace data owner,
+// separate from the slots handed out by NewGoroutineSlot, and is inherited
+// through Start.
+type GoroutineHooks struct {
+	// Start is called when a goroutine starts from a goroutine which has
+	// trace data attached. It is passed the PC of the new goroutine's
+	// function, the new goroutine's ID and the parent's trace data, and
+	// returns the trace data to attach to the new goroutine.
+	//
+	// Start runs on the parent goroutine, as part of its go statement,
+	// before the new goroutine is able to run. Goroutines started by
+	// timers, such as the one running a time.AfterFunc callback, have no
+	// parent goroutine, so instead take the trace data of the goroutine
+	// which set the timer, without Start being called. Nor is Exit called
+	// as they exit with that trace data, which they only share.
+	//
+	// As Start runs, GoroutineName returns the name which the new
+	// goroutine inherits, which is the parent's, for naming its span more
+	// helpfully than after the function at pc. A goroutine which then
+	// names itself is reported to Rename.
+	Start func(pc uintptr, goRoutineID uint64, parentTraceData unsafe.Pointer) unsafe.Pointer
+
+	// Exit is called when a goroutine with trace data attached exits.
+	//
+	// Exit runs on the exiting goroutine, after its function
+	// has returned and its deferred calls have run.
+	Exit func(goRoutineID uint64, traceData unsafe.Pointer)
+
+	// Rename is called as a goroutine with trace data attached sets its
+	// name with SetGoroutineName, on that goroutine, with the goroutine's
+	// full name, for renaming the span started for it by Start.
+	Rename func(goRoutineID uint64, traceData unsafe.Pointer, name string)
+}
+
+// RegisterGoroutineHooks registers the hooks called by the runtime
+// as goroutines start and exit.
+func RegisterGoroutineHooks(h GoroutineHooks) {
+	setGoroutineHooks(h.Start, h.Exit, h.Rename)
+}
+
+// SetTraceData attaches traceData to the calling goroutine, such as as it
+// starts serving a request, replacing any trace data it had without
+// calling GoroutineHooks.Exit. Setting nil detaches the trace data.
+func SetTraceData(traceData unsafe.Pointer) {
+	setTraceData(traceData)
+}
+
+// TraceData returns the trace data attached to the calling goroutine,
+// or nil if it has none.
+func TraceData() unsafe.Pointer {
+	return traceData()
+}
+
+// SetCreationStackDepth sets the number of frames of a goroutine's stack
+// to record, as it starts a goroutine while it has trace data, on the
+// goroutine it starts. The recorded stack is returned by CreationStack,
+// and in the GoroutineRecord of each goroutine in a Root. The default,
+// zero, records no stacks, as recording them makes starting goroutines
+// slower.
+func SetCreationStackDepth(depth int) {
+	setCreationStackDepth(depth)
+}
+
+// CreationStack returns the stack recorded as the calling goroutine was
+// started, as return program counters in the form returned by
+// runtime.Callers, starting at the function containing the go statement.
+// Called from the Start hook, it returns the stack recorded for the
+// goroutine being started instead. It returns nil if no stack was
+// recorded; see SetCreationStackDepth. The caller must not modify the
+// returned slice.
+func CreationStack() []uintptr {
+	return creationStack()
+}
+
+// EachGoroutineTraceData calls fn with the ID and trace data of each
+// goroutine which has trace data attached, such as to report what
+// each goroutine was doing as the program crashes.
+//
+// The trace data is collected without stopping other goroutines, which
+// may have since exited or changed their trace data by the time fn is
+// called, and which may be concurrently using the trace data fn is passed.
+func EachGoroutineTraceData(fn func(goRoutineID uint64, traceData unsafe.Pointer)) {
+	eachGoroutineTraceData(fn)
+}
+
+// setGoroutineHooks is implemented in the runtime package.
+func setGoroutineHooks(start func(uintptr, uint64, unsafe.Pointer) unsafe.Pointer, exit func(uint64, unsafe.Pointer), rename func(uint64, unsafe.Pointer, string))
+
+// eachGoroutineTraceData is implemented in the runtime package.
+func eachGoroutineTraceData(fn func(uint64, unsafe.Pointer))
+
+// SetGoroutineName sets the name of the calling goroutine, such as
+// "cache-refresher", to identify it in goroutine tracebacks, profiles
+// and spans. Goroutines it then starts inherit its name, and prefix any
+// name they set with it, joined by "/": a goroutine named "fetch" started
+// by one named "api" is named "api/fetch". Setting the empty name reverts
+// to the inherited name. Goroutines started by timers have no name.
+// SetGoroutineName panics if name contains a double quote or a control
+// character.
+//
+// The name is shown in the goroutine's traceback header, after its
+// state, in the GoroutineRecord of a Root, and as the "goroutine"
+// profiler label of the goroutine, which is inherited as the name is
+// (see runtime/pprof.Labels). Replacing the goroutine's profiler labels,
+// as with runtime/pprof.SetGoroutineLabels, removes the label.
+func SetGoroutineName(name string) {
+	full := setGoroutineName(name)
+	l := labels.Add(labels.Get(), []string{"goroutine", full})
+	if full == "" {
+		delete(*(*labels.Map)(l), "goroutine")
+	}
+	labels.Set(l)
+}
+
+// GoroutineName returns the name of the calling goroutine,
+// or "" if it has none. See SetGoroutineName.
+func GoroutineName() string {
+	return goroutineName()
+}
+
+// GoroutineID returns the ID of the calling goroutine, as shown in its
+// traceback header, and passed to the hooks. IDs are not reused while the
+// program runs, but identify goroutines only for debugging and for
+// correlating logs and traces: programs must not use them to change
+// their behavior, such as by keeping goroutine-local state keyed by them.
+func GoroutineID() uint64 {
+	return getgoid()
+}
+
+// ParentGoroutineID returns the ID of the goroutine which started the
+// calling goroutine, whether or not either had trace data at the time,
+// so that the tree of goroutines working for a request can be rebuilt.
+// It returns 0 for the main goroutine, and for goroutines started by
+// timers, such as those running time.AfterFunc callbacks. The parent
+// may have exited.
+func ParentGoroutineID() uint64 {
+	return getParentGoid()
+}
+
+// These are implemented in the runtime package.
+func setCreationStackDepth(depth int)
+func creationStack() []uintptr
+func setGoroutineName(name string) string
+func goroutineName() string
+func getgoid() uint64
+func getParentGoid() uint64
+func setTraceData(traceData unsafe.Pointer)
+func traceData() unsafe.Pointer
@@ -0,0 +1,394 @@
+package hooks
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/hooks/internal/labels"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+// A recorder records the calls made to the hooks registered by a test.
+// As hooks are global, and called for every goroutine, tests only record
+// the calls made with their trace data, or for the goroutine under test.
+// Its counters may be used by hooks which must not allocate.
+type recorder struct {
+	traceData unsafe.Pointer // set before the hooks are registered
+	goID      atomic.Uint64  // the goroutine under test, if any
+
+	counts   [4]atomic.Int64
+	badTimes atomic.Int32
+
+	mu     sync.Mutex
+	events []string
+}
+
+// recordHooks calls register to register the hooks of the test, which
+// record their calls for traceData with the recorder it returns, and
+// calls unregister as the test finishes.
+func recordHooks(t *testing.T, traceData unsafe.Pointer, register func(r *recorder), unregister func()) *recorder {
+	r := &recorder{traceData: traceData}
+	register(r)
+	t.Cleanup(unregister)
+	return r
+}
+
+// traced reports whether a call with traceData is for the test.
+func (r *recorder) traced(traceData unsafe.Pointer) bool {
+	return traceData == r.traceData
+}
+
+// onG reports whether a call for goroutine goID is for the test.
+func (r *recorder) onG(goID uint64) bool {
+	return goID == r.goID.Load()
+}
+
+// add adds n to counter i, returning its new value, and count returns it.
+func (r *recorder) add(i int, n int64) int64 {
+	return r.counts[i].Add(n)
+}
+
+func (r *recorder) count(i int) int64 {
+	return r.counts[i].Load()
+}
+
+// times counts the calls with an invalid start and end time.
+func (r *recorder) times(start, end int64) {
+	if start <= 0 || end < start {
+		r.badTimes.Add(1)
+	}
+}
+
+// log records an event, and logged returns the events recorded.
+func (r *recorder) log(event string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *recorder) logged() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.events...)
+}
+
+func TestGoroutineHooks(t *testing.T) {
+	parent, child := new(int), new(int)
+
+	var nstarted, nexited atomic.Int32
+	var started, exited atomic.Pointer[byte]
+	RegisterGoroutineHooks(GoroutineHooks{
+		Start: func(pc uintptr, goRoutineID uint64, parentTraceData unsafe.Pointer) unsafe.Pointer {
+			started.Store((*byte)(parentTraceData))
+			nstarted.Add(1)
+			return unsafe.Pointer(child)
+		},
+		Exit: func(goRoutineID uint64, traceData unsafe.Pointer) {
+			exited.Store((*byte)(traceData))
+			nexited.Add(1)
+		},
+	})
+	defer RegisterGoroutineHooks(GoroutineHooks{})
+
+	SetTraceData(unsafe.Pointer(parent))
+	defer SetTraceData(nil)
+
+	got := make(chan unsafe.Pointer)
+	go func() {
+		got <- TraceData()
+	}()
+	if data := <-got; data != unsafe.Pointer(child) {
+		t.Errorf("child goroutine has trace data %p; want %p", data, child)
+	}
+
+	// Wait for the exit hook, which runs after the goroutine's function returns.
+	for i := 0; nexited.Load() == 0; i++ {
+		if i > 1e6 {
+			t.Fatal("exit hook never called")
+		}
+		runtime.Gosched()
+	}
+
+	if n, p := nstarted.Load(), unsafe.Pointer(started.Load()); n != 1 || p != unsafe.Pointer(parent) {
+		t.Errorf("start hook called %d times with %p; want once with %p", n, p, parent)
+	}
+	if n, p := nexited.Load(), unsafe.Pointer(exited.Load()); n != 1 || p != unsafe.Pointer(child) {
+		t.Errorf("exit hook called %d times with %p; want once with %p", n, p, child)
+	}
+}
+
+func TestAfterFuncInheritsTraceData(t *testing.T) {
+	var nexited atomic.Int32
+	RegisterGoroutineHooks(GoroutineHooks{
+		Exit: func(goRoutineID uint64, traceData unsafe.Pointer) {
+			nexited.Add(1)
+		},
+	})
+	defer RegisterGoroutineHooks(GoroutineHooks{})
+
+	data := new(int)
+	SetTraceData(unsafe.Pointer(data))
+	got := make(chan unsafe.Pointer, 1)
+	time.AfterFunc(time.Millisecond, func() {
+		got <- TraceData()
+	})
+	SetTraceData(nil)
+
+	if p := <-got; p != unsafe.Pointer(data) {
+		t.Errorf("AfterFunc callback has trace data %p; want %p", p, data)
+	}
+
+	// Wait for the callback's goroutine to exit, which must not call
+	// the exit hook for the trace data it shares with us.
+	for {
+		n := 0
+		EachGoroutineTraceData(func(uint64, unsafe.Pointer) { n++ })
+		if n == 0 {
+			break
+		}
+		runtime.Gosched()
+	}
+	if n := nexited.Load(); n != 0 {
+		t.Errorf("exit hook called %d times for the AfterFunc callback; want 0", n)
+	}
+}
+
+func TestGoroutineHooksNotCalledWithoutTraceData(t *testing.T) {
+	called := false
+	RegisterGoroutineHooks(GoroutineHooks{
+		Start: func(uintptr, uint64, unsafe.Pointer) unsafe.Pointer {
+			called = true
+			return nil
+		},
+	})
+	defer RegisterGoroutineHooks(GoroutineHooks{})
+
+	done := make(chan struct{})
+	go close(done)
+	<-done
+
+	if called {
+		t.Error("start hook called for a goroutine without trace data")
+	}
+}
+
+// goroutineLabels returns the current goroutine's profiler labels.
+func goroutineLabels() map[string]string {
+	if p := labels.Get(); p != nil {
+		return *(*labels.Map)(p)
+	}
+	return nil
+}
+
+func TestEachGoroutineTraceData(t *testing.T) {
+	data := new(int)
+	ready := make(chan uint64)
+	release := make(chan struct{})
+	go func() {
+		SetTraceData(unsafe.Pointer(data))
+		defer SetTraceData(nil)
+		ready <- GoroutineID()
+		<-release
+	}()
+	id := <-ready
+	defer close(release)
+
+	found := false
+	EachGoroutineTraceData(func(goRoutineID uint64, traceData unsafe.Pointer) {
+		if goRoutineID == id {
+			found = true
+			if traceData != unsafe.Pointer(data) {
+				t.Errorf("goroutine %d has trace data %p; want %p", id, traceData, data)
+			}
+		}
+	})
+	if !found {
+		t.Errorf("goroutine %d not found", id)
+	}
+}
+
+func TestGoroutineID(t *testing.T) {
+	buf := make([]byte, 64)
+	header := string(buf[:runtime.Stack(buf, false)])
+	if want := fmt.Sprintf("goroutine %d [", GoroutineID()); !strings.HasPrefix(header, want) {
+		t.Errorf("stack %q does not start with %q", header, want)
+	}
+
+	root := NewRoot()
+	root.Enter()
+	ids := make(chan [2]uint64)
+	release := make(chan struct{})
+	defer close(release)
+	go func() {
+		ids <- [2]uint64{GoroutineID(), ParentGoroutineID()}
+		<-release
+	}()
+	root.Leave()
+	child := <-ids
+	if child[1] != GoroutineID() {
+		t.Errorf("child goroutine %d has parent %d; want %d", child[0], child[1], GoroutineID())
+	}
+	if records := root.Goroutines(); len(records) != 1 || records[0].ID != child[0] || records[0].ParentID != GoroutineID() {
+		t.Errorf("root goroutines are %+v; want goroutine %d with parent %d", records, child[0], GoroutineID())
+	}
+
+	time.AfterFunc(time.Millisecond, func() {
+		ids <- [2]uint64{GoroutineID(), ParentGoroutineID()}
+	})
+	if timer := <-ids; timer[1] != 0 {
+		t.Errorf("timer goroutine %d has parent %d; want 0", timer[0], timer[1])
+	}
+}
+
+func TestGoroutineName(t *testing.T) {
+	var renamed atomic.Pointer[string]
+	RegisterGoroutineHooks(GoroutineHooks{
+		Start: func(pc uintptr, goRoutineID uint64, parentTraceData unsafe.Pointer) unsafe.Pointer {
+			return parentTraceData
+		},
+		Rename: func(goRoutineID uint64, traceData unsafe.Pointer, name string) {
+			renamed.Store(&name)
+		},
+	})
+	defer RegisterGoroutineHooks(GoroutineHooks{})
+
+	root := NewRoot()
+	ready := make(chan uint64)
+	release := make(chan struct{})
+	defer close(release)
+	errs := make(chan string, 10)
+	go func() {
+		root.Enter()
+		defer root.Leave()
+		SetGoroutineName("api")
+		if name, label := GoroutineName(), goroutineLabels()["goroutine"]; name != "api" || label != "api" {
+			errs <- fmt.Sprintf("name %q, label %q after SetGoroutineName(\"api\"); want \"api\"", name, label)
+		}
+		SetTraceData(unsafe.Pointer(new(int)))
+		go func() {
+			defer SetTraceData(nil)
+			if name := GoroutineName(); name != "api" {
+				errs <- fmt.Sprintf("child inherited name %q; want \"api\"", name)
+			}
+			SetGoroutineName("fetch")
+			SetGoroutineName("worker")
+			if name := renamed.Load(); name == nil || *name != "api/worker" {
+				errs <- fmt.Sprintf("Rename called with %v; want \"api/worker\"", name)
+			}
+			if name, label := GoroutineName(), goroutineLabels()["goroutine"]; name != "api/worker" || label != "api/worker" {
+				errs <- fmt.Sprintf("child name %q, label %q; want \"api/worker\"", name, label)
+			}
+			ready <- GoroutineID()
+			<-release
+		}()
+		SetTraceData(nil)
+		SetGoroutineName("")
+		if name := GoroutineName(); name != "" {
+			errs <- fmt.Sprintf("name %q after SetGoroutineName(\"\"); want none", name)
+		}
+		if _, ok := goroutineLabels()["goroutine"]; ok {
+			errs <- "goroutine label not removed"
+		}
+	}()
+	id := <-ready
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	buf := make([]byte, 1<<20)
+	stacks := string(buf[:runtime.Stack(buf, true)])
+	want := fmt.Sprintf("goroutine %d [chan receive] \"api/worker\":\n", id)
+	if !strings.Contains(stacks, want) {
+		t.Errorf("stacks do not contain %q:\n%s", want, stacks)
+	}
+	if records := root.Goroutines(); len(records) != 1 || records[0].Name != "api/worker" {
+		t.Errorf("root goroutines are %+v; want one named \"api/worker\"", records)
+	}
+}
+
+//go:noinline
+func startForTest(root *Root, release chan struct{}, stack chan []uintptr) {
+	go func() {
+		root.Enter()
+		defer root.Leave()
+		stack <- CreationStack()
+		<-release
+	}()
+}
+
+func TestCreationStack(t *testing.T) {
+	SetCreationStackDepth(4)
+	defer SetCreationStackDepth(0)
+	var startStack atomic.Pointer[[]uintptr]
+	RegisterGoroutineHooks(GoroutineHooks{
+		Start: func(pc uintptr, goRoutineID uint64, parentTraceData unsafe.Pointer) unsafe.Pointer {
+			s := CreationStack()
+			startStack.Store(&s)
+			return nil
+		},
+	})
+	defer RegisterGoroutineHooks(GoroutineHooks{})
+
+	root := NewRoot()
+	release := make(chan struct{})
+	defer close(release)
+	stack := make(chan []uintptr)
+
+	// Goroutines started without trace data record no stack.
+	startForTest(root, release, stack)
+	if s := <-stack; s != nil {
+		t.Errorf("untraced goroutine has creation stack %v; want nil", s)
+	}
+
+	SetTraceData(unsafe.Pointer(new(int)))
+	startForTest(root, release, stack)
+	SetTraceData(nil)
+	s := <-stack
+	if len(s) == 0 || len(s) > 4 {
+		t.Fatalf("creation stack has %d frames; want 1 to 4", len(s))
+	}
+	if p := startStack.Load(); p == nil || len(*p) == 0 || &(*p)[0] != &s[0] {
+		t.Errorf("CreationStack in Start hook returned %v; want the new goroutine's %v", p, s)
+	}
+	frames := runtime.CallersFrames(s)
+	for i, want := range []string{"runtime/hooks.startForTest", "runtime/hooks.TestCreationStack"} {
+		f, _ := frames.Next()
+		if f.Function != want {
+			t.Errorf("creation stack frame %d is %s; want %s", i, f.Function, want)
+		}
+	}
+
+	var found bool
+	for _, r := range root.Goroutines() {
+		if len(r.CreationStack) != 0 {
+			if found || &r.CreationStack[0] != &s[0] {
+				t.Errorf("goroutine %d has creation stack %v; want only the traced goroutine's %v", r.ID, r.CreationStack, s)
+			}
+			found = true
+		}
+	}
+	if !found {
+		t.Error("no goroutine in root has a creation stack")
+	}
+}
+
+func TestGoroutineNameInvalid(t *testing.T) {
+	for _, name := range []string{`a"b`, "a\nb", "a\x00"} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("SetGoroutineName(%q) did not panic", name)
+				}
+			}()
+			SetGoroutineName(name)
+		}()
+	}
+	if name := GoroutineName(); name != "" {
+		t.Errorf("name %q after invalid names; want none", name)
+	}
+}
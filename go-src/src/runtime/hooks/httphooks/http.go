@@ -0,0 +1,98 @@
+// Package httphooks lets a library register the functions which the
+// net/http package calls as requests are served and sent, as with the
+// hooks of the runtime/hooks package. It is separate from runtime/hooks so
+// that programs registering only runtime hooks need not link net/http.
+package httphooks
+
+import (
+	"net/http"
+	"runtime/hooks/internal/span"
+)
+
+// HTTPHooks are called by the net/http package as requests are
+// served by a http.Server and sent by a http.Transport.
+type HTTPHooks struct {
+	// HandlerStart is called on the serving goroutine
+	// before the request's handler is invoked.
+	HandlerStart func(req *http.Request)
+
+	// HandlerEnd is called on the serving goroutine once the handler
+	// has returned. If the handler panicked, didPanic will be true.
+	HandlerEnd func(didPanic bool)
+
+	// StartRoundTrip is called before a request is sent. It returns the
+	// request to send, which may be a copy of req with added headers or
+	// context.
+	StartRoundTrip func(req *http.Request) *http.Request
+
+	// EndRoundTrip is called once the response has been received
+	// or the round trip has failed.
+	EndRoundTrip func(resp *http.Response, err error)
+
+	// Labels is called after HandlerStart, and returns profiler label
+	// key/value pairs, such as the request's route, span name and trace
+	// ID, to add to the serving goroutine's labels while the handler
+	// runs, and to goroutines it starts. See runtime/pprof.Labels.
+	// The goroutine's previous labels are restored before HandlerEnd.
+	Labels func(req *http.Request) []string
+}
+
+// RegisterHTTPHooks registers the hooks called by the net/http package.
+//
+// While the execution tracer is running (see runtime/trace), each
+// handler invocation is also recorded as a "http.Handler" user task,
+// and each round trip made by one as a "http.RoundTrip" region in it.
+func RegisterHTTPHooks(h HTTPHooks) {
+	var handlerStart func(*http.Request)
+	var handlerEnd func(bool)
+	if start, end, labels := h.HandlerStart, h.HandlerEnd, h.Labels; start != nil || end != nil || labels != nil {
+		handlerStart = func(req *http.Request) {
+			if start != nil {
+				start(req)
+			}
+			var kv []string
+			if labels != nil {
+				kv = labels(req)
+			}
+			span.Start(req.Context(), "http.Handler", func() string {
+				return req.Method + " " + req.URL.Path
+			}, labels != nil, kv)
+		}
+		handlerEnd = func(didPanic bool) {
+			span.End("http.Handler")
+			if end != nil {
+				end(didPanic)
+			}
+		}
+	}
+
+	var startRoundTrip func(*http.Request) *http.Request
+	var endRoundTrip func(*http.Response, error)
+	if start, end := h.StartRoundTrip, h.EndRoundTrip; start != nil || end != nil {
+		startRoundTrip = func(req *http.Request) *http.Request {
+			if start != nil {
+				req = start(req)
+			}
+			span.Start(nil, "http.RoundTrip", func() string {
+				return req.Method + " " + req.URL.Redacted()
+			}, false, nil)
+			return req
+		}
+		endRoundTrip = func(resp *http.Response, err error) {
+			span.End("http.RoundTrip")
+			if end != nil {
+				end(resp, err)
+			}
+		}
+	}
+
+	setHTTPHooks(handlerStart, handlerEnd, startRoundTrip, endRoundTrip)
+}
+
+// setHTTPHooks is implemented in the net/http package.
+func setHTTPHooks(
+	handlerStart func(*http.Request),
+	handlerEnd func(bool),
+	startRoundTrip func(*http.Request) *http.Request,
+	endRoundTrip func(*http.Response, error),
+)
@@ -0,0 +1,242 @@
+package httphooks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"internal/trace"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"runtime/hooks/internal/labels"
+	"runtime/pprof"
+	rtrace "runtime/trace"
+	"sync"
+	"testing"
+)
+
+func TestHTTPHooks(t *testing.T) {
+	var mu sync.Mutex
+	var events []string
+	record := func(event string) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, event)
+	}
+
+	RegisterHTTPHooks(HTTPHooks{
+		HandlerStart: func(req *http.Request) { record("handler start " + req.URL.Path) },
+		HandlerEnd:   func(didPanic bool) { record("handler end") },
+		StartRoundTrip: func(req *http.Request) *http.Request {
+			record("round trip start")
+			req = req.Clone(req.Context())
+			req.Header.Set("X-Hooked", "yes")
+			return req
+		},
+		EndRoundTrip: func(resp *http.Response, err error) { record("round trip end") },
+	})
+	defer RegisterHTTPHooks(HTTPHooks{})
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, r.Header.Get("X-Hooked"))
+	}))
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/path")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != "yes" {
+		t.Errorf("handler saw X-Hooked %q; want %q", body, "yes")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := map[string]bool{
+		"round trip start":    true,
+		"handler start /path": true,
+		"round trip end":      true,
+	}
+	for _, e := range events {
+		delete(want, e)
+	}
+	for e := range want {
+		t.Errorf("missing hook call %q; got %q", e, events)
+	}
+}
+
+// goroutineLabels returns the current goroutine's profiler labels.
+func goroutineLabels() map[string]string {
+	if p := labels.Get(); p != nil {
+		return *(*labels.Map)(p)
+	}
+	return nil
+}
+
+func TestHTTPHooksLabels(t *testing.T) {
+	type handlerLabels struct{ start, handler, end map[string]string }
+	got := make(chan handlerLabels, 1)
+	var l handlerLabels
+	RegisterHTTPHooks(HTTPHooks{
+		HandlerStart: func(req *http.Request) { l.start = goroutineLabels() },
+		HandlerEnd: func(didPanic bool) {
+			l.end = goroutineLabels()
+			got <- l
+		},
+		Labels: func(req *http.Request) []string { return []string{"route", req.URL.Path} },
+	})
+	defer RegisterHTTPHooks(HTTPHooks{})
+
+	// The server's goroutines inherit the labels of the goroutine which
+	// starts it, which the handler's labels are added to.
+	pprof.SetGoroutineLabels(pprof.WithLabels(context.Background(), pprof.Labels("test", "http")))
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		l.handler = goroutineLabels()
+	}))
+	pprof.SetGoroutineLabels(context.Background())
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/path")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	l = <-got
+	base := fmt.Sprint(map[string]string{"test": "http"})
+	if s := fmt.Sprint(l.start); s != base {
+		t.Errorf("labels in HandlerStart = %s; want %s", s, base)
+	}
+	if s, want := fmt.Sprint(l.handler), fmt.Sprint(map[string]string{"test": "http", "route": "/path"}); s != want {
+		t.Errorf("labels in handler = %s; want %s", s, want)
+	}
+	if s := fmt.Sprint(l.end); s != base {
+		t.Errorf("labels in HandlerEnd = %s; want %s", s, base)
+	}
+}
+
+func TestHTTPHooksHandlerEndAlwaysCalled(t *testing.T) {
+	type end struct {
+		didPanic bool
+		labels   map[string]string
+	}
+	ends := make(chan end, 1)
+	RegisterHTTPHooks(HTTPHooks{
+		HandlerEnd: func(didPanic bool) {
+			ends <- end{didPanic, goroutineLabels()}
+		},
+		Labels: func(req *http.Request) []string { return []string{"route", req.URL.Path} },
+	})
+	defer RegisterHTTPHooks(HTTPHooks{})
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/abort":
+			panic(http.ErrAbortHandler)
+		case "/hijack":
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			conn.Close()
+		}
+	}))
+	defer ts.Close()
+
+	for _, tt := range []struct {
+		path     string
+		didPanic bool
+	}{
+		{"/abort", true},
+		{"/hijack", false},
+	} {
+		if resp, err := http.Get(ts.URL + tt.path); err == nil {
+			resp.Body.Close()
+		}
+		e := <-ends
+		if e.didPanic != tt.didPanic {
+			t.Errorf("%s: HandlerEnd called with didPanic %v; want %v", tt.path, e.didPanic, tt.didPanic)
+		}
+		if _, ok := e.labels["route"]; ok {
+			t.Errorf("%s: labels in HandlerEnd = %v; want no route", tt.path, e.labels)
+		}
+	}
+}
+
+func TestHTTPHooksExecutionTrace(t *testing.T) {
+	if rtrace.IsEnabled() {
+		t.Skip("skipping because -test.trace is set")
+	}
+	RegisterHTTPHooks(HTTPHooks{
+		HandlerStart:   func(req *http.Request) {},
+		StartRoundTrip: func(req *http.Request) *http.Request { return req },
+	})
+	defer RegisterHTTPHooks(HTTPHooks{})
+
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/outer" {
+			resp, err := http.Get(ts.URL + "/inner")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			resp.Body.Close()
+		}
+	}))
+	defer ts.Close()
+
+	buf := new(bytes.Buffer)
+	if err := rtrace.Start(buf); err != nil {
+		t.Fatalf("failed to start tracing: %v", err)
+	}
+	resp, err := http.Get(ts.URL + "/outer")
+	if err == nil {
+		resp.Body.Close()
+	}
+	rtrace.Stop()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := trace.Parse(buf, "")
+	if err == trace.ErrTimeOrder {
+		t.Skipf("skipping trace: %v", err)
+	}
+	if err != nil {
+		t.Fatalf("failed to parse trace: %v", err)
+	}
+
+	// Describe each task by the message logged as it started, and
+	// record the regions started in each task.
+	tasks := map[uint64]string{0: "none"}
+	var got []string
+	for _, e := range res.Events {
+		switch e.Type {
+		case trace.EvUserLog:
+			if e.SArgs[0] == "http.Handler" {
+				tasks[e.Args[0]] = e.SArgs[1]
+			}
+		case trace.EvUserRegion:
+			if e.Args[1] == 0 { // region start
+				got = append(got, fmt.Sprintf("%s in task %s", e.SArgs[0], tasks[e.Args[0]]))
+			}
+		}
+	}
+	want := []string{
+		"http.RoundTrip in task none",
+		"http.RoundTrip in task GET /outer",
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("got regions %q; want %q", got, want)
+	}
+	if len(tasks) != 3 || tasks[0] != "none" {
+		t.Errorf("got tasks %q; want tasks for /outer and /inner", tasks)
+	}
+}
@@ -0,0 +1,46 @@
+package httphooks
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"runtime/hooks"
+)
+
+// RootHandler returns an HTTP handler which dumps the goroutines of a
+// single request, in the style of the net/http/pprof handlers, for
+// finding out why the request is hanging. The request is identified by
+// the "id" query parameter, such as its trace ID, which lookup maps to
+// the hooks.Root its goroutines are in, or nil if there is no such request.
+//
+// The handler stops the world to collect the stacks, so like the
+// net/http/pprof handlers it should only be served to operators.
+func RootHandler(lookup func(id string) *hooks.Root) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		id := req.FormValue("id")
+		if id == "" {
+			serveRootError(w, http.StatusBadRequest, "missing id")
+			return
+		}
+		r := lookup(id)
+		if r == nil {
+			serveRootError(w, http.StatusNotFound, "unknown id")
+			return
+		}
+		// Buffer the dump, so an error can still be served as one.
+		var buf bytes.Buffer
+		if err := r.WriteGoroutines(&buf); err != nil {
+			serveRootError(w, http.StatusInternalServerError, "could not dump goroutines: "+err.Error())
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		buf.WriteTo(w)
+	})
+}
+
+func serveRootError(w http.ResponseWriter, status int, txt string) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(status)
+	fmt.Fprintln(w, txt)
+}
@@ -0,0 +1,60 @@
+package httphooks
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"runtime/hooks"
+	"strings"
+	"testing"
+)
+
+func TestRootHandler(t *testing.T) {
+	root := hooks.NewRoot()
+	release := make(chan struct{})
+	defer close(release)
+	entered := make(chan struct{})
+	go func() {
+		root.Enter()
+		defer root.Leave()
+		close(entered)
+		<-release
+	}()
+	<-entered
+
+	srv := httptest.NewServer(RootHandler(func(id string) *hooks.Root {
+		if id == "trace-1" {
+			return root
+		}
+		return nil
+	}))
+	defer srv.Close()
+
+	for _, tt := range []struct {
+		query  string
+		status int
+		want   []string
+	}{
+		{"?id=trace-1", http.StatusOK, []string{"goroutine ", " [chan receive]:\n", "runtime/hooks/httphooks.TestRootHandler.func1(...)\n\t", "created by runtime/hooks/httphooks.TestRootHandler(...)\n"}},
+		{"?id=trace-2", http.StatusNotFound, []string{"unknown id"}},
+		{"", http.StatusBadRequest, []string{"missing id"}},
+	} {
+		resp, err := http.Get(srv.URL + tt.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != tt.status {
+			t.Errorf("%q: status %d; want %d", tt.query, resp.StatusCode, tt.status)
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(string(body), want) {
+				t.Errorf("%q: response does not contain %q:\n%s", tt.query, want, body)
+			}
+		}
+	}
+}
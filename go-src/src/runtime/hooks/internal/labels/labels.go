@@ -0,0 +1,31 @@
+// Package labels reads and replaces the profiler labels of the current
+// goroutine, for runtime/hooks and the HTTP and SQL hook packages.
+package labels
+
+import (
+	"unsafe"
+)
+
+// Map must match runtime/pprof.labelMap.
+type Map map[string]string
+
+// Add returns a copy of the profiler labels prev with the given
+// key/value pairs added.
+func Add(prev unsafe.Pointer, kv []string) unsafe.Pointer {
+	var parent Map
+	if prev != nil {
+		parent = *(*Map)(prev)
+	}
+	labels := make(Map, len(parent)+len(kv)/2)
+	for k, v := range parent {
+		labels[k] = v
+	}
+	for i := 0; i < len(kv); i += 2 {
+		labels[kv[i]] = kv[i+1]
+	}
+	return unsafe.Pointer(&labels)
+}
+
+// These are implemented in the runtime package.
+func Get() unsafe.Pointer
+func Set(labels unsafe.Pointer)
@@ -0,0 +1,112 @@
+// Package span keeps the stack of spans started on each goroutine by the
+// HTTP and SQL hooks, and records them as profiler labels and execution
+// trace tasks and regions.
+package span
+
+import (
+	"context"
+	"runtime/hooks"
+	"runtime/hooks/internal/labels"
+	"runtime/trace"
+	"unsafe"
+)
+
+// slot holds the stack of spans started on each goroutine.
+// It is not inherited by new goroutines.
+var slot = hooks.NewGoroutineSlot(hooks.GoroutineSlotHooks{})
+
+// A span records what Start changed as a HTTP handler, round trip
+// or SQL query started, for End to undo as it ends.
+type span struct {
+	kind string
+
+	setLabels bool
+	labels    unsafe.Pointer // profiler labels to restore, if setLabels
+
+	ctx    context.Context // context carrying task
+	task   *trace.Task
+	region *trace.Region
+
+	next *span
+}
+
+// Start starts a span of the given kind on the current goroutine.
+// If setLabels is set, the given profiler label key/value pairs are
+// added to the goroutine's labels.
+//
+// While the execution tracer is running, the span is recorded as a user
+// task, a child of any task in parent, if parent is non-nil, or else as
+// a user region in the task of the goroutine's innermost task span,
+// along with the message returned by msg.
+func Start(parent context.Context, kind string, msg func() string, setLabels bool, kv []string) {
+	if setLabels && len(kv)%2 != 0 {
+		panic("hooks: uneven number of profiler label key/value pairs")
+	}
+
+	s := &span{kind: kind, next: (*span)(slot.Get())}
+	debugSpan("start", kind, msg)
+	if setLabels {
+		s.setLabels = true
+		s.labels = labels.Get()
+		labels.Set(labels.Add(s.labels, kv))
+	}
+	if trace.IsEnabled() {
+		var ctx context.Context
+		if parent != nil {
+			s.ctx, s.task = trace.NewTask(parent, kind)
+			ctx = s.ctx
+		} else {
+			ctx = s.next.context()
+			s.region = trace.StartRegion(ctx, kind)
+		}
+		trace.Log(ctx, kind, msg())
+	}
+	slot.Set(unsafe.Pointer(s))
+}
+
+// End ends the innermost span of the given kind on the current
+// goroutine, along with any spans started after it which were never
+// ended, such as by a query whose goroutine panicked. It does nothing
+// if there is no such span.
+func End(kind string) {
+	top := (*span)(slot.Get())
+	var s *span
+	for s = top; s != nil && s.kind != kind; s = s.next {
+	}
+	if s == nil {
+		return
+	}
+	slot.Set(unsafe.Pointer(s.next))
+	for ; top != s.next; top = top.next {
+		top.end()
+	}
+}
+
+// end undoes what Start did for s.
+func (s *span) end() {
+	debugSpan("end", s.kind, nil)
+	if s.region != nil {
+		s.region.End()
+	}
+	if s.task != nil {
+		s.task.End()
+	}
+	if s.setLabels {
+		labels.Set(s.labels)
+	}
+}
+
+// context returns the context of the innermost span from s with an
+// execution trace task.
+func (s *span) context() context.Context {
+	for ; s != nil; s = s.next {
+		if s.task != nil {
+			return s.ctx
+		}
+	}
+	return context.Background()
+}
+
+// debugSpan is implemented in the runtime package. With
+// GODEBUG=tracinghooks=2, it prints the start or end of a span.
+func debugSpan(event, kind string, msg func() string)
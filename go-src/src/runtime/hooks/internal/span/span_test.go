@@ -0,0 +1,61 @@
+package span
+
+import (
+	"fmt"
+	"internal/testenv"
+	"os"
+	"os/exec"
+	"runtime/hooks/internal/labels"
+	"strings"
+	"testing"
+)
+
+func goroutineLabels() string {
+	if p := labels.Get(); p != nil {
+		return fmt.Sprint(*(*labels.Map)(p))
+	}
+	return fmt.Sprint(map[string]string(nil))
+}
+
+func TestEndUnwindsNestedSpans(t *testing.T) {
+	msg := func() string { return "" }
+	base := goroutineLabels()
+	Start(nil, "outer", msg, true, []string{"span", "outer"})
+	Start(nil, "inner", msg, true, []string{"span", "inner"})
+
+	// Ending a span which was never started leaves the stack alone.
+	End("other")
+	if got, want := goroutineLabels(), fmt.Sprint(map[string]string{"span": "inner"}); got != want {
+		t.Errorf("labels after ending unknown span = %s; want %s", got, want)
+	}
+
+	// Ending the outer span ends the inner one, which was never ended.
+	End("outer")
+	if got := goroutineLabels(); got != base {
+		t.Errorf("labels after ending outer span = %s; want %s", got, base)
+	}
+	if s := slot.Get(); s != nil {
+		t.Errorf("span stack not empty after ending outer span")
+	}
+}
+
+func TestDebugSpan(t *testing.T) {
+	if os.Getenv("SPAN_TEST_DEBUG") != "" {
+		Start(nil, "sql.Query", func() string { return "SELECT 1" }, false, nil)
+		End("sql.Query")
+		return
+	}
+	testenv.MustHaveExec(t)
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestDebugSpan$")
+	cmd.Env = append(os.Environ(), "SPAN_TEST_DEBUG=1", "GODEBUG=tracinghooks=2")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("%v\n%s", err, out)
+	}
+	for _, want := range []string{" span start sql.Query SELECT 1\n", " span end sql.Query\n"} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("output does not contain %q:\n%s", want, out)
+		}
+	}
+}
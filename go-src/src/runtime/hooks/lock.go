@@ -0,0 +1,39 @@
+package hooks
+
+import (
+	"unsafe"
+)
+
+// LockHooks are called by the runtime as goroutines with trace data wait
+// for a contended sync.Mutex or sync.RWMutex, allowing the time spent
+// waiting to be attributed to the waiting goroutine's trace data, and the
+// wait to be blamed on the trace data of the goroutine holding the lock.
+//
+// The hooks run on the waiting goroutine. They must not block, nor lock
+// a sync.Mutex or sync.RWMutex, as that might call them recursively.
+type LockHooks struct {
+	// Contended is called as the goroutine finds the lock held and is
+	// about to wait for it. It is passed a value identifying the lock,
+	// which is not necessarily its address, the reason it will be shown
+	// as waiting, and the current time, as a monotonic clock reading in
+	// nanoseconds.
+	Contended func(traceData unsafe.Pointer, lock uintptr, reason WaitReason, now int64)
+
+	// Acquired is called after each call to Contended, once the goroutine
+	// has stopped waiting. A sync.Mutex may have been taken by another
+	// goroutine in the meantime, in which case the goroutine calls
+	// Contended and waits again. holderTraceData is the trace data of the
+	// goroutine whose unlock woke it, or nil if that goroutine had none
+	// or the lock became free before it started to wait.
+	Acquired func(traceData, holderTraceData unsafe.Pointer, lock uintptr, reason WaitReason, now int64)
+}
+
+// RegisterLockHooks registers the hooks called by the runtime
+// as goroutines wait for contended locks.
+func RegisterLockHooks(h LockHooks) {
+	setLockHooks(h.Contended, h.Acquired)
+}
+
+// setLockHooks is implemented in the runtime package,
+// which receives each WaitReason as a uint8.
+func setLockHooks(contended func(unsafe.Pointer, uintptr, WaitReason, int64), acquired func(unsafe.Pointer, unsafe.Pointer, uintptr, WaitReason, int64))
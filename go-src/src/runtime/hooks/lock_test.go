@@ -0,0 +1,60 @@
+package hooks
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"unsafe"
+)
+
+func TestLockHooks(t *testing.T) {
+	// The hooks run on the waiting goroutine, which closes done
+	// after they've been called.
+	var (
+		contended []unsafe.Pointer
+		holders   []unsafe.Pointer
+		nWaiting  atomic.Int32
+	)
+	RegisterLockHooks(LockHooks{
+		Contended: func(traceData unsafe.Pointer, lock uintptr, reason WaitReason, now int64) {
+			if reason.String() != "sync.Mutex.Lock" {
+				t.Errorf("Contended called with reason %q; want %q", reason, "sync.Mutex.Lock")
+			}
+			contended = append(contended, traceData)
+			nWaiting.Add(1)
+		},
+		Acquired: func(traceData, holderTraceData unsafe.Pointer, lock uintptr, reason WaitReason, now int64) {
+			holders = append(holders, holderTraceData)
+		},
+	})
+	defer RegisterLockHooks(LockHooks{})
+
+	holder, waiter := new(int), new(int)
+	SetTraceData(unsafe.Pointer(holder))
+	defer SetTraceData(nil)
+
+	var mu sync.Mutex
+	mu.Lock()
+	done := make(chan struct{})
+	go func() {
+		SetTraceData(unsafe.Pointer(waiter))
+		defer SetTraceData(nil)
+		mu.Lock()
+		mu.Unlock()
+		close(done)
+	}()
+
+	for nWaiting.Load() == 0 {
+		runtime.Gosched()
+	}
+	mu.Unlock()
+	<-done
+
+	if len(contended) != 1 || contended[0] != unsafe.Pointer(waiter) {
+		t.Errorf("Contended called with %v; want [%p]", contended, waiter)
+	}
+	if len(holders) != 1 || holders[0] != unsafe.Pointer(holder) {
+		t.Errorf("Acquired called with holders %v; want [%p]", holders, holder)
+	}
+}
@@ -0,0 +1,33 @@
+package hooks
+
+import (
+	"unsafe"
+)
+
+// PanicHooks are called by the runtime as goroutines with trace data panic
+// and recover, allowing the panic to be recorded against the trace data
+// which was active when it started, even if it is later recovered.
+type PanicHooks struct {
+	// Panic is called on a goroutine as it starts to panic with value,
+	// before any deferred calls run. stack holds the return program
+	// counters of the goroutine's stack, as returned by runtime.Callers,
+	// starting at the function which called panic, and is truncated to
+	// 64 entries.
+	//
+	// If Panic itself panics, that panic proceeds without calling Panic.
+	Panic func(traceData unsafe.Pointer, value any, stack []uintptr)
+
+	// Recover is called on a goroutine as a deferred call recovers
+	// a panic with value, once the deferred call has returned and before
+	// the goroutine resumes normal execution.
+	Recover func(traceData unsafe.Pointer, value any)
+}
+
+// RegisterPanicHooks registers the hooks called by the runtime
+// as goroutines panic and recover.
+func RegisterPanicHooks(h PanicHooks) {
+	setPanicHooks(h.Panic, h.Recover)
+}
+
+// setPanicHooks is implemented in the runtime package.
+func setPanicHooks(panic func(unsafe.Pointer, any, []uintptr), recover func(unsafe.Pointer, any))
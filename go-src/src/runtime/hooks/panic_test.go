@@ -0,0 +1,99 @@
+package hooks
+
+import (
+	"runtime"
+	"testing"
+	"unsafe"
+)
+
+//go:noinline
+func panicForTest(v any) {
+	panic(v)
+}
+
+func TestPanicHooks(t *testing.T) {
+	var (
+		panics, recovers []any
+		panicData        unsafe.Pointer
+		stack            []uintptr
+	)
+	RegisterPanicHooks(PanicHooks{
+		Panic: func(traceData unsafe.Pointer, value any, s []uintptr) {
+			panics = append(panics, value)
+			panicData = traceData
+			stack = s
+		},
+		Recover: func(traceData unsafe.Pointer, value any) {
+			recovers = append(recovers, value)
+		},
+	})
+	defer RegisterPanicHooks(PanicHooks{})
+
+	data := new(int)
+	SetTraceData(unsafe.Pointer(data))
+	func() {
+		defer SetTraceData(nil)
+		defer func() { recover() }()
+		panicForTest("boom")
+	}()
+
+	if len(panics) != 1 || panics[0] != "boom" {
+		t.Errorf("Panic called with %v; want [boom]", panics)
+	}
+	if panicData != unsafe.Pointer(data) {
+		t.Errorf("Panic called with trace data %p; want %p", panicData, data)
+	}
+	if len(recovers) != 1 || recovers[0] != "boom" {
+		t.Errorf("Recover called with %v; want [boom]", recovers)
+	}
+	if len(stack) == 0 {
+		t.Fatal("Panic called with empty stack")
+	}
+	frame, _ := runtime.CallersFrames(stack).Next()
+	if want := "runtime/hooks.panicForTest"; frame.Function != want {
+		t.Errorf("stack starts at %s; want %s", frame.Function, want)
+	}
+
+	// A panic without trace data doesn't call the hooks.
+	func() {
+		defer func() { recover() }()
+		panicForTest("quiet")
+	}()
+	if len(panics) != 1 || len(recovers) != 1 {
+		t.Errorf("hooks called for panic without trace data: %v, %v", panics, recovers)
+	}
+}
+
+func TestPanicHookPanics(t *testing.T) {
+	calls := 0
+	RegisterPanicHooks(PanicHooks{
+		Panic: func(traceData unsafe.Pointer, value any, stack []uintptr) {
+			calls++
+			// A panic recovered within the hook doesn't
+			// let the hook's own panic call it again.
+			func() {
+				defer func() { recover() }()
+				panic("inner")
+			}()
+			panic("hook")
+		},
+	})
+	defer RegisterPanicHooks(PanicHooks{})
+
+	SetTraceData(unsafe.Pointer(new(int)))
+	defer SetTraceData(nil)
+	for i := 1; i <= 2; i++ {
+		var got any
+		func() {
+			defer func() { got = recover() }()
+			panicForTest("boom")
+		}()
+
+		if calls != i {
+			t.Errorf("Panic called %d times after %d panics; want %d", calls, i, i)
+		}
+		if got != "hook" {
+			t.Errorf("recovered %v; want hook", got)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package hooks
+
+import (
+	"unsafe"
+)
+
+// A WaitReason explains why a goroutine was parked,
+// such as "chan receive", "sync.Mutex.Lock", "IO wait" or "sleep".
+// The values are internal to the runtime and may change between
+// releases, so only the String form should be relied upon.
+type WaitReason uint8
+
+// String returns the same description of r as
+// is shown in goroutine tracebacks.
+func (r WaitReason) String() string {
+	return waitReasonString(uint8(r))
+}
+
+// ParkHooks are called by the runtime as goroutines with trace data
+// block and are unblocked, allowing blocked time to be attributed to
+// the goroutine's trace data. Each hook is passed the goroutine's wait
+// reason and the current time, as a monotonic clock reading in nanoseconds.
+//
+// The hooks are called from deep within the scheduler, with runtime locks
+// held and possibly on the system stack, so they must be short and simple.
+// They must not block, allocate memory, start goroutines, or call anything
+// which might; they should only record the time into the trace data.
+type ParkHooks struct {
+	// Park is called on a goroutine as it is about to park. The runtime
+	// may still hold the lock the goroutine parks under, which is only
+	// released once it has parked: the channel locks as it blocks in a
+	// channel operation or select, or the semaphore's or condition
+	// variable's lock as it blocks in the sync package. So Park must not
+	// operate on channels or sync primitives, as it could deadlock.
+	Park func(goRoutineID uint64, traceData unsafe.Pointer, reason WaitReason, now int64)
+
+	// Ready is called when a parked goroutine is made runnable again,
+	// on whichever goroutine or scheduler thread readied it. Its writes
+	// to the trace data happen before the readied goroutine next reads
+	// its trace data from the runtime.
+	Ready func(goRoutineID uint64, traceData unsafe.Pointer, reason WaitReason, now int64)
+}
+
+// RegisterParkHooks registers the hooks called by the runtime
+// as goroutines park and are readied.
+func RegisterParkHooks(h ParkHooks) {
+	setParkHooks(h.Park, h.Ready)
+}
+
+// setParkHooks is implemented in the runtime package,
+// which receives each WaitReason as a uint8.
+func setParkHooks(park, ready func(uint64, unsafe.Pointer, WaitReason, int64))
+
+// waitReasonString is implemented in the runtime package.
+func waitReasonString(reason uint8) string
@@ -0,0 +1,82 @@
+package hooks
+
+import (
+	"runtime"
+	"testing"
+	"unsafe"
+)
+
+type parkEvent struct {
+	traceData unsafe.Pointer
+	reason    string
+	now       int64
+}
+
+// The counters of the park hooks' recorder.
+const (
+	nParks = iota
+	nReadies
+)
+
+func TestParkHooks(t *testing.T) {
+	// The park hooks must not allocate, so they record into fixed size
+	// buffers, and only record events from the goroutine under test.
+	var parks, readies [16]parkEvent
+	record := func(r *recorder, events *[16]parkEvent, n int, goRoutineID uint64, traceData unsafe.Pointer, reason WaitReason, now int64) {
+		if !r.onG(goRoutineID) {
+			return
+		}
+		if i := r.add(n, 1) - 1; int(i) < len(events) {
+			events[i] = parkEvent{traceData, reason.String(), now}
+		}
+	}
+	r := recordHooks(t, nil, func(r *recorder) {
+		RegisterParkHooks(ParkHooks{
+			Park: func(goRoutineID uint64, traceData unsafe.Pointer, reason WaitReason, now int64) {
+				record(r, &parks, nParks, goRoutineID, traceData, reason, now)
+			},
+			Ready: func(goRoutineID uint64, traceData unsafe.Pointer, reason WaitReason, now int64) {
+				record(r, &readies, nReadies, goRoutineID, traceData, reason, now)
+			},
+		})
+	}, func() { RegisterParkHooks(ParkHooks{}) })
+
+	data := new(int)
+	ch := make(chan int)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		r.goID.Store(GoroutineID())
+		SetTraceData(unsafe.Pointer(data))
+		defer SetTraceData(nil)
+		<-ch
+
+		// Reading our trace data synchronizes with the ready hook.
+		TraceData()
+	}()
+
+	// Wait for the goroutine to block on the channel.
+	for r.count(nParks) == 0 {
+		runtime.Gosched()
+	}
+	ch <- 1
+	<-done
+
+	if n := r.count(nParks); n != 1 {
+		t.Fatalf("park hook called %d times; want 1", n)
+	}
+	if n := r.count(nReadies); n != 1 {
+		t.Fatalf("ready hook called %d times; want 1", n)
+	}
+	for _, e := range []parkEvent{parks[0], readies[0]} {
+		if e.traceData != unsafe.Pointer(data) {
+			t.Errorf("hook called with trace data %p; want %p", e.traceData, data)
+		}
+		if e.reason != "chan receive" {
+			t.Errorf("hook called with reason %q; want %q", e.reason, "chan receive")
+		}
+	}
+	if readies[0].now < parks[0].now {
+		t.Errorf("ready time %d is before park time %d", readies[0].now, parks[0].now)
+	}
+}
@@ -0,0 +1,142 @@
+package hooks
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"runtime"
+	"unsafe"
+)
+
+// A Root groups the goroutines started on behalf of a single request,
+// so that goroutines outliving the request can be found, such as to
+// report them as leaked when the request's root span ends.
+//
+// A goroutine joins a root by calling Enter, and every goroutine it then
+// starts joins the root too, as do the goroutines they start, and so on.
+// Goroutines leave their root as they exit. A goroutine is in at most
+// one root at a time.
+type Root struct {
+	r unsafe.Pointer
+}
+
+// NewRoot returns a new root, with no goroutines in it.
+func NewRoot() *Root {
+	return &Root{r: newRoot()}
+}
+
+// Enter makes the calling goroutine join r, leaving any root it was in.
+func (r *Root) Enter() {
+	enterRoot(r.r)
+}
+
+// Leave makes the calling goroutine leave r, if it is in r. Goroutines
+// it has already started stay in r.
+func (r *Root) Leave() {
+	leaveRoot(r.r)
+}
+
+// CurrentRoot returns the root the calling goroutine is in, or nil if
+// it is in none. The result refers to the same group of goroutines as
+// the Root which was entered, but is not necessarily the same pointer.
+func CurrentRoot() *Root {
+	r := currentRoot()
+	if r == nil {
+		return nil
+	}
+	return &Root{r: r}
+}
+
+// Count returns the number of live goroutines in r.
+func (r *Root) Count() int {
+	return rootCount(r.r)
+}
+
+// A GoroutineRecord describes a live goroutine in a Root.
+type GoroutineRecord struct {
+	ID            uint64      // goroutine ID
+	ParentID      uint64      // ID of the goroutine which started it; see ParentGoroutineID
+	CreatedBy     uintptr     // PC of the go statement which started the goroutine
+	CreationStack []uintptr   // stack which started the goroutine; see SetCreationStackDepth
+	State         string      // state as shown in tracebacks, such as "running" or "chan receive, 5 minutes"
+	Name          string      // name, if any; see SetGoroutineName
+	Stack0        [32]uintptr // stack trace for this goroutine, 0-terminated
+}
+
+// Stack returns the stack trace associated with the record,
+// a prefix of r.Stack0, as runtime.StackRecord.Stack does.
+func (r *GoroutineRecord) Stack() []uintptr {
+	for i, v := range r.Stack0 {
+		if v == 0 {
+			return r.Stack0[0:i]
+		}
+	}
+	return r.Stack0[0:]
+}
+
+// Goroutines returns a record of each live goroutine in r, including
+// its stack. Like runtime.GoroutineProfile, it stops the world while it
+// collects the stacks, so it is relatively expensive, and should only be
+// called once Count has found goroutines of interest.
+func (r *Root) Goroutines() []GoroutineRecord {
+	for {
+		// Allow for goroutines starting while the records are allocated.
+		records := make([]GoroutineRecord, r.Count()+10)
+		if n, ok := rootGoroutines(r.r, records); ok {
+			return records[:n]
+		}
+	}
+}
+
+// WriteGoroutines writes the ID, state and stack of each live goroutine
+// in r to w, in a format similar to a goroutine traceback, followed by
+// the goroutine's creation stack, if it was recorded. As with Goroutines,
+// collecting the stacks stops the world.
+func (r *Root) WriteGoroutines(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	for i, g := range r.Goroutines() {
+		if i > 0 {
+			fmt.Fprintln(bw)
+		}
+		fmt.Fprintf(bw, "goroutine %d [%s]", g.ID, g.State)
+		if g.Name != "" {
+			fmt.Fprintf(bw, " %q", g.Name)
+		}
+		fmt.Fprintln(bw, ":")
+		printFrames(bw, g.Stack())
+		if g.CreatedBy != 0 {
+			fmt.Fprint(bw, "created by ")
+			printFrames(bw, []uintptr{g.CreatedBy})
+		}
+		if len(g.CreationStack) > 0 {
+			fmt.Fprintln(bw, "creation stack:")
+			printFrames(bw, g.CreationStack)
+		}
+	}
+	return bw.Flush()
+}
+
+// printFrames writes the frames of the call stack stk to w,
+// as a traceback would.
+func printFrames(w io.Writer, stk []uintptr) {
+	frames := runtime.CallersFrames(stk)
+	for {
+		frame, more := frames.Next()
+		if frame.Function == "" {
+			fmt.Fprintf(w, "%#x\n", frame.PC)
+		} else if frame.Function != "runtime.goexit" {
+			fmt.Fprintf(w, "%s(...)\n\t%s:%d +%#x\n", frame.Function, frame.File, frame.Line, frame.PC-frame.Entry)
+		}
+		if !more {
+			break
+		}
+	}
+}
+
+// These are implemented in the runtime package.
+func newRoot() unsafe.Pointer
+func enterRoot(r unsafe.Pointer)
+func leaveRoot(r unsafe.Pointer)
+func currentRoot() unsafe.Pointer
+func rootCount(r unsafe.Pointer) int
+func rootGoroutines(r unsafe.Pointer, records []GoroutineRecord) (n int, ok bool)
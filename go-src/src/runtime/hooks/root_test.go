@@ -0,0 +1,95 @@
+package hooks
+
+import (
+	"bytes"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestRoot(t *testing.T) {
+	root := NewRoot()
+	release := make(chan struct{})
+	started := make(chan uint64)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		root.Enter()
+		defer root.Leave()
+		go func() {
+			// Goroutines started by goroutines in the root join it too.
+			go func() {
+				started <- GoroutineID()
+				<-release
+			}()
+			started <- GoroutineID()
+			<-release
+		}()
+	}()
+	<-done
+	ids := map[uint64]bool{<-started: true, <-started: true}
+
+	if n := root.Count(); n != 2 {
+		t.Errorf("root has %d goroutines; want 2", n)
+	}
+	records := root.Goroutines()
+	if len(records) != 2 {
+		t.Fatalf("Goroutines returned %d records; want 2", len(records))
+	}
+	for _, r := range records {
+		if !ids[r.ID] {
+			t.Errorf("Goroutines returned goroutine %d; want one of %v", r.ID, ids)
+		}
+		if fn := runtime.FuncForPC(r.CreatedBy); fn == nil || !strings.HasPrefix(fn.Name(), "runtime/hooks.TestRoot.func") {
+			t.Errorf("goroutine %d created by %v; want a TestRoot closure", r.ID, fn)
+		}
+		if len(r.Stack()) == 0 {
+			t.Errorf("goroutine %d has empty stack", r.ID)
+		}
+	}
+
+	close(release)
+	for i := 0; root.Count() != 0; i++ {
+		if i > 1e6 {
+			t.Fatalf("root still has %d goroutines", root.Count())
+		}
+		runtime.Gosched()
+	}
+}
+
+func TestWriteGoroutines(t *testing.T) {
+	root := NewRoot()
+	release := make(chan struct{})
+	defer close(release)
+	entered := make(chan *Root)
+	go func() {
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+		root.Enter()
+		defer root.Leave()
+		entered <- CurrentRoot()
+		<-release
+	}()
+	if r := <-entered; r == nil || r.r != root.r {
+		t.Errorf("CurrentRoot in root = %v; want %v", r, root)
+	}
+	if r := CurrentRoot(); r != nil {
+		t.Errorf("CurrentRoot outside root = %v; want nil", r)
+	}
+
+	var buf bytes.Buffer
+	if err := root.WriteGoroutines(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"goroutine ",
+		" [chan receive, locked to thread]:\n",
+		"runtime/hooks.TestWriteGoroutines.func1(...)\n\t",
+		"created by runtime/hooks.TestWriteGoroutines(...)\n",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("output does not contain %q:\n%s", want, &buf)
+		}
+	}
+}
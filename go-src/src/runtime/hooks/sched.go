@@ -0,0 +1,93 @@
+package hooks
+
+import (
+	"strconv"
+	"unsafe"
+)
+
+// SchedHooks are called by the scheduler as goroutines with trace data
+// start and stop running, allowing the CPU time of a goroutine, and the
+// time it spends runnable but waiting for a CPU, to be attributed to its
+// trace data. Each hook is passed the current time, as a monotonic clock
+// reading in nanoseconds.
+//
+// A goroutine runs from each call to Run until the next call to Stop, or
+// until it exits, which is reported by the goroutine Exit hook instead.
+// Time spent in system calls counts as running.
+//
+// The hooks are called from deep within the scheduler, with the same
+// restrictions as ParkHooks: they must not block, allocate memory, start
+// goroutines, or call anything which might.
+type SchedHooks struct {
+	// Run is called as a goroutine starts running, on the thread
+	// which is about to run it.
+	Run func(goRoutineID uint64, traceData unsafe.Pointer, now int64)
+
+	// Stop is called as a goroutine stops running, on the thread which
+	// was running it. runnable reports whether the goroutine is waiting
+	// for a CPU, as when it is preempted or leaves a system call,
+	// rather than waiting to be made runnable, as reported by ParkHooks.
+	// As a goroutine leaves a system call with no CPU free, Stop is only
+	// called just before Run, once it has one, but is passed the time
+	// the goroutine stopped.
+	Stop func(goRoutineID uint64, traceData unsafe.Pointer, runnable bool, now int64)
+}
+
+// RegisterSchedHooks registers the hooks called by the scheduler
+// as goroutines start and stop running.
+func RegisterSchedHooks(h SchedHooks) {
+	setSchedHooks(h.Run, h.Stop)
+}
+
+// setSchedHooks is implemented in the runtime package.
+func setSchedHooks(run func(uint64, unsafe.Pointer, int64), stop func(uint64, unsafe.Pointer, bool, int64))
+
+// A SchedClass is the scheduling class of a goroutine. When picking a
+// goroutine to run, the scheduler prefers runnable goroutines of the high
+// class, and puts off those of the background class while others wait,
+// so that latency sensitive work, such as serving user requests, keeps
+// its latency under load while background work uses spare CPU time.
+//
+// Classes are a hint, not a strict priority: background goroutines still
+// run from time to time on a busy CPU, and a goroutine of any class which
+// yields, as with runtime.Gosched, or is preempted waits behind the other
+// runnable goroutines, as usual.
+type SchedClass uint8
+
+const (
+	SchedNormal     SchedClass = iota // the default class
+	SchedHigh                         // preferred over other classes
+	SchedBackground                   // runs after other classes
+)
+
+// String returns the name of c: "normal", "high" or "background".
+func (c SchedClass) String() string {
+	switch c {
+	case SchedNormal:
+		return "normal"
+	case SchedHigh:
+		return "high"
+	case SchedBackground:
+		return "background"
+	}
+	return "SchedClass(" + strconv.Itoa(int(c)) + ")"
+}
+
+// SetSchedClass sets the scheduling class of the calling goroutine,
+// along with its trace data. Goroutines it then starts inherit its
+// class, except those started by timers, which run in the normal class.
+func SetSchedClass(class SchedClass) {
+	if class > SchedBackground {
+		panic("hooks: invalid scheduling class")
+	}
+	setSchedClass(uint8(class))
+}
+
+// CurrentSchedClass returns the scheduling class of the calling goroutine.
+func CurrentSchedClass() SchedClass {
+	return SchedClass(schedClass())
+}
+
+// These are implemented in the runtime package.
+func setSchedClass(class uint8)
+func schedClass() uint8
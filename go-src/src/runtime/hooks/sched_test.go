@@ -0,0 +1,76 @@
+package hooks
+
+import (
+	"runtime"
+	"testing"
+	"unsafe"
+)
+
+// The counters of the sched hooks' recorder.
+const (
+	nRuns = iota
+	nRunnableStops
+	nWaitingStops
+)
+
+func TestSchedHooks(t *testing.T) {
+	r := recordHooks(t, nil, func(r *recorder) {
+		RegisterSchedHooks(SchedHooks{
+			Run: func(goRoutineID uint64, traceData unsafe.Pointer, now int64) {
+				if r.onG(goRoutineID) {
+					r.add(nRuns, 1)
+				}
+			},
+			Stop: func(goRoutineID uint64, traceData unsafe.Pointer, runnable bool, now int64) {
+				switch {
+				case !r.onG(goRoutineID):
+				case runnable:
+					r.add(nRunnableStops, 1)
+				default:
+					r.add(nWaitingStops, 1)
+				}
+			},
+		})
+	}, func() { RegisterSchedHooks(SchedHooks{}) })
+
+	ch := make(chan int)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		r.goID.Store(GoroutineID())
+		SetTraceData(unsafe.Pointer(new(int)))
+		defer SetTraceData(nil)
+		runtime.Gosched()
+		<-ch
+	}()
+
+	for r.count(nWaitingStops) == 0 {
+		runtime.Gosched()
+	}
+	ch <- 1
+	<-done
+
+	if n := r.count(nRunnableStops); n < 1 {
+		t.Errorf("Stop called %d times with runnable set; want at least 1", n)
+	}
+	if n := r.count(nWaitingStops); n < 1 {
+		t.Errorf("Stop called %d times with runnable unset; want at least 1", n)
+	}
+	if runs, stops := r.count(nRuns), r.count(nRunnableStops)+r.count(nWaitingStops); runs != stops {
+		t.Errorf("Run called %d times; want %d, once after each Stop", runs, stops)
+	}
+}
+
+func TestSchedClass(t *testing.T) {
+	defer SetSchedClass(CurrentSchedClass())
+
+	SetSchedClass(SchedHigh)
+	inherited := make(chan SchedClass)
+	go func() { inherited <- CurrentSchedClass() }()
+	if c := <-inherited; c != SchedHigh {
+		t.Errorf("child goroutine has class %v; want %v", c, SchedHigh)
+	}
+	if s := SchedBackground.String(); s != "background" {
+		t.Errorf("SchedBackground.String() = %q; want %q", s, "background")
+	}
+}
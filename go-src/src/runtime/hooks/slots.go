@@ -0,0 +1,73 @@
+package hooks
+
+import (
+	"unsafe"
+)
+
+// A GoroutineSlot is a per-goroutine data slot allocated by NewGoroutineSlot.
+//
+// Each goroutine carries its own pointer in every slot, which lets several
+// libraries attach state to goroutines without stepping on each other.
+// The zero GoroutineSlot is not a valid slot.
+type GoroutineSlot struct {
+	index int
+}
+
+// GoroutineSlotHooks are called by the runtime to manage the
+// data in a GoroutineSlot as goroutines start and exit.
+type GoroutineSlotHooks struct {
+	// Inherit is called when a goroutine starts from a goroutine which has
+	// data in the slot. It is passed the PC of the new goroutine's function,
+	// the new goroutine's ID and the parent's data, and returns the data to
+	// store in the new goroutine's slot.
+	//
+	// If Inherit is nil, new goroutines start with an empty slot.
+	// Like GoroutineHooks.Start, Inherit runs on the parent goroutine.
+	Inherit func(pc uintptr, goRoutineID uint64, parentData unsafe.Pointer) unsafe.Pointer
+
+	// Cleanup is called when a goroutine with data in the slot exits.
+	// The slot is cleared afterwards, whether or not Cleanup is set.
+	// Like GoroutineHooks.Exit, Cleanup runs on the exiting goroutine.
+	Cleanup func(goRoutineID uint64, data unsafe.Pointer)
+}
+
+// NewGoroutineSlot allocates a new per-goroutine data slot managed by the
+// given hooks. Slots are never freed, and only a small fixed number are
+// available, so a library should allocate its slots once from an init function.
+//
+// NewGoroutineSlot panics if every slot has already been allocated.
+func NewGoroutineSlot(h GoroutineSlotHooks) GoroutineSlot {
+	index := newGoroutineSlot(h.Inherit, h.Cleanup)
+	if index < 0 {
+		panic("hooks: all goroutine data slots are in use")
+	}
+	return GoroutineSlot{index: index}
+}
+
+// Get returns the data stored in the slot for the current goroutine.
+func (s GoroutineSlot) Get() unsafe.Pointer {
+	s.check()
+	return getGoroutineSlot(s.index)
+}
+
+// Set stores data in the slot for the current goroutine.
+// Setting nil empties the slot without calling its Cleanup hook.
+func (s GoroutineSlot) Set(data unsafe.Pointer) {
+	s.check()
+	setGoroutineSlot(s.index, data)
+}
+
+func (s GoroutineSlot) check() {
+	if s.index == 0 {
+		panic("hooks: use of unallocated GoroutineSlot")
+	}
+}
+
+// newGoroutineSlot is implemented in the runtime package.
+func newGoroutineSlot(inherit func(uintptr, uint64, unsafe.Pointer) unsafe.Pointer, cleanup func(uint64, unsafe.Pointer)) int
+
+// getGoroutineSlot is implemented in the runtime package.
+func getGoroutineSlot(index int) unsafe.Pointer
+
+// setGoroutineSlot is implemented in the runtime package.
+func setGoroutineSlot(index int, data unsafe.Pointer)
@@ -0,0 +1,84 @@
+package hooks
+
+import (
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"unsafe"
+)
+
+type slotData struct{ name string }
+
+// Slots are never freed, so the test slots are allocated once rather
+// than by each run of TestGoroutineSlots, and their cleanup hooks record
+// with the recorder of the running test, if any.
+var (
+	slotRecorder atomic.Pointer[recorder]
+
+	inheritedSlot = NewGoroutineSlot(GoroutineSlotHooks{
+		Inherit: func(pc uintptr, goRoutineID uint64, parentData unsafe.Pointer) unsafe.Pointer {
+			return unsafe.Pointer(&slotData{name: (*slotData)(parentData).name + " child"})
+		},
+		Cleanup: recordCleanup,
+	})
+	notInheritedSlot = NewGoroutineSlot(GoroutineSlotHooks{Cleanup: recordCleanup})
+)
+
+func recordCleanup(goRoutineID uint64, data unsafe.Pointer) {
+	if r := slotRecorder.Load(); r != nil {
+		r.log((*slotData)(data).name)
+	}
+}
+
+func TestGoroutineSlots(t *testing.T) {
+	if inheritedSlot == notInheritedSlot {
+		t.Fatalf("NewGoroutineSlot returned the same slot twice")
+	}
+
+	r := recordHooks(t, nil, slotRecorder.Store, func() { slotRecorder.Store(nil) })
+
+	inheritedSlot.Set(unsafe.Pointer(&slotData{name: "a"}))
+	notInheritedSlot.Set(unsafe.Pointer(&slotData{name: "b"}))
+	defer inheritedSlot.Set(nil)
+	defer notInheritedSlot.Set(nil)
+
+	if got := TraceData(); got != nil {
+		t.Errorf("trace data is %p; want nil", got)
+	}
+
+	type result struct{ inherited, notInherited unsafe.Pointer }
+	got := make(chan result)
+	go func() {
+		got <- result{inheritedSlot.Get(), notInheritedSlot.Get()}
+		notInheritedSlot.Set(unsafe.Pointer(&slotData{name: "c"}))
+	}()
+	res := <-got
+	if res.inherited == nil || (*slotData)(res.inherited).name != "a child" {
+		t.Errorf("inherited slot in child = %v; want %q", res.inherited, "a child")
+	}
+	if res.notInherited != nil {
+		t.Errorf("non-inherited slot in child = %p; want nil", res.notInherited)
+	}
+
+	var cleaned []string
+	for i := 0; len(cleaned) < 2; i++ {
+		if i > 1e6 {
+			t.Fatal("cleanup hooks never called")
+		}
+		runtime.Gosched()
+		cleaned = r.logged()
+	}
+	if (cleaned[0] != "a child" || cleaned[1] != "c") && (cleaned[0] != "c" || cleaned[1] != "a child") {
+		t.Errorf("cleanup hooks called with %q; want [a child c]", cleaned)
+	}
+}
+
+func TestZeroGoroutineSlotPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Get on the zero GoroutineSlot did not panic")
+		}
+	}()
+	var s GoroutineSlot
+	s.Get()
+}
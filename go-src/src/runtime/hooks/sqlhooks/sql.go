@@ -0,0 +1,83 @@
+// Package sqlhooks lets a library register the functions which the
+// database/sql package calls as queries are run, as with the hooks of the
+// runtime/hooks package. It is separate from runtime/hooks so that
+// programs registering only runtime hooks need not link database/sql.
+package sqlhooks
+
+import (
+	_ "database/sql"
+	"runtime/hooks/internal/span"
+	"strings"
+	"unicode"
+)
+
+// SQLHooks are called by the database/sql package as queries are run.
+type SQLHooks struct {
+	// QueryStart is called before a query or exec is sent to the driver.
+	QueryStart func(query string)
+
+	// QueryEnd is called once the query or exec has finished.
+	QueryEnd func(err error)
+
+	// Labels is called after QueryStart, and returns profiler label
+	// key/value pairs, such as the span name, to add to the calling
+	// goroutine's labels while the query runs. See runtime/pprof.Labels.
+	// The goroutine's previous labels are restored before QueryEnd.
+	//
+	// Labels is passed the query's operation, its first keyword, such as
+	// "SELECT", rather than its full text, which may hold literal values,
+	// unless FullQueryText is set.
+	Labels func(query string) []string
+
+	// FullQueryText records the full text of each query in execution
+	// trace regions and passes it to Labels, rather than its operation.
+	FullQueryText bool
+}
+
+// RegisterSQLHooks registers the hooks called by the database/sql package.
+//
+// While the execution tracer is running (see runtime/trace), each query
+// is also recorded as a "sql.Query" user region, in the task of the HTTP
+// handler running on the same goroutine, if any, logged with the query's
+// operation, or its full text if FullQueryText is set.
+func RegisterSQLHooks(h SQLHooks) {
+	var queryStart func(string)
+	var queryEnd func(error)
+	if start, end, labels, full := h.QueryStart, h.QueryEnd, h.Labels, h.FullQueryText; start != nil || end != nil || labels != nil {
+		queryStart = func(query string) {
+			if start != nil {
+				start(query)
+			}
+			text := query
+			if !full {
+				text = operation(query)
+			}
+			var kv []string
+			if labels != nil {
+				kv = labels(text)
+			}
+			span.Start(nil, "sql.Query", func() string {
+				return text
+			}, labels != nil, kv)
+		}
+		queryEnd = func(err error) {
+			span.End("sql.Query")
+			if end != nil {
+				end(err)
+			}
+		}
+	}
+	setSQLHooks(queryStart, queryEnd)
+}
+
+// operation returns the first keyword of query, in upper case.
+func operation(query string) string {
+	query = strings.TrimLeftFunc(query, unicode.IsSpace)
+	if i := strings.IndexFunc(query, func(r rune) bool { return !unicode.IsLetter(r) }); i >= 0 {
+		query = query[:i]
+	}
+	return strings.ToUpper(query)
+}
+
+// setSQLHooks is implemented in the database/sql package.
+func setSQLHooks(queryStart func(string), queryEnd func(error))
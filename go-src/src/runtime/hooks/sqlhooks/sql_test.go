@@ -0,0 +1,18 @@
+package sqlhooks
+
+import "testing"
+
+func TestOperation(t *testing.T) {
+	for _, tt := range []struct {
+		query, want string
+	}{
+		{"SELECT * FROM users WHERE email = 'a@example.com'", "SELECT"},
+		{"\n\tinsert into users(name) values ('x')", "INSERT"},
+		{"Update users SET name = ?", "UPDATE"},
+		{"", ""},
+	} {
+		if got := operation(tt.query); got != tt.want {
+			t.Errorf("operation(%q) = %q; want %q", tt.query, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package hooks
+
+import (
+	"unsafe"
+)
+
+// SyscallHooks are called as goroutines with trace data return from
+// system calls and from C functions called through cgo, allowing the
+// time spent outside of Go, such as in blocking file I/O, DNS lookups by
+// the C library, or other C libraries, to be attributed to their trace
+// data. Timing system calls makes them slower, so they are only timed
+// while the hooks are registered.
+type SyscallHooks struct {
+	// Syscall is called on a goroutine as it returns from a system call,
+	// or from a C function if cgo is set, which it entered at start and
+	// left at end, as monotonic clock readings in nanoseconds.
+	//
+	// Syscall is called from the runtime's system call path, so it must
+	// not block or allocate memory. Any system calls it makes itself are
+	// not timed.
+	Syscall func(traceData unsafe.Pointer, cgo bool, start, end int64)
+}
+
+// RegisterSyscallHooks registers the hooks called as goroutines
+// with trace data return from system calls and cgo calls.
+func RegisterSyscallHooks(h SyscallHooks) {
+	setSyscallHooks(h.Syscall)
+}
+
+// setSyscallHooks is implemented in the runtime package.
+func setSyscallHooks(syscall func(unsafe.Pointer, bool, int64, int64))
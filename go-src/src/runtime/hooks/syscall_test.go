@@ -0,0 +1,59 @@
+package hooks
+
+import (
+	"os"
+	"testing"
+	"unsafe"
+)
+
+// The counters of the syscall hook's recorder.
+const (
+	syscallCalls = iota
+	syscallCgoCalls
+)
+
+func TestSyscallHooks(t *testing.T) {
+	data := unsafe.Pointer(new(int))
+	r := recordHooks(t, data, func(r *recorder) {
+		RegisterSyscallHooks(SyscallHooks{Syscall: func(traceData unsafe.Pointer, cgo bool, start, end int64) {
+			if !r.traced(traceData) {
+				return
+			}
+			r.add(syscallCalls, 1)
+			if cgo {
+				r.add(syscallCgoCalls, 1)
+			}
+			r.times(start, end)
+		}})
+	}, func() { RegisterSyscallHooks(SyscallHooks{}) })
+
+	// System calls made without trace data aren't timed.
+	f, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	if n := r.count(syscallCalls); n != 0 {
+		t.Fatalf("syscall hook called %d times without trace data", n)
+	}
+
+	SetTraceData(data)
+	f, err = os.Open(os.DevNull)
+	if err == nil {
+		err = f.Close()
+	}
+	SetTraceData(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n := r.count(syscallCalls); n < 2 {
+		t.Errorf("syscall hook called %d times; want at least 2, for open and close", n)
+	}
+	if n := r.count(syscallCgoCalls); n != 0 {
+		t.Errorf("syscall hook reported %d cgo calls; want 0", n)
+	}
+	if n := r.badTimes.Load(); n != 0 {
+		t.Errorf("syscall hook called with bad times %d times", n)
+	}
+}
@@ -0,0 +1,47 @@
+package hooks
+
+import (
+	"unsafe"
+)
+
+// TracebackHooks let the library owning the trace data identify it in
+// goroutine tracebacks, such as those printed by runtime.Stack, as the
+// program crashes with an unrecovered panic and as it exits on SIGQUIT.
+type TracebackHooks struct {
+	// Label returns a short string identifying traceData, such as a
+	// trace ID and span name, which is printed quoted after the status
+	// in the header of the traceback of each goroutine with that trace
+	// data, and after its name, if any:
+	//
+	//	goroutine 42 [chan receive] "4bf92f3577b34da6 GET /users":
+	//
+	// An empty result prints nothing. As tracebacks may be printed
+	// where the runtime can't call Label, such as for fatal errors or as
+	// the program exits on SIGQUIT, Label is called as trace data is
+	// attached to a goroutine or returned by GoroutineHooks.Start, and
+	// as the goroutine calls SetGoroutineName, and the result is kept
+	// for its tracebacks until its trace data changes. Label runs on
+	// the goroutine attaching the trace data, starting the goroutine or
+	// naming itself, so must not block. Goroutines started by timers,
+	// and those whose trace data was set before Label was registered,
+	// have no label. SetTracebackLabel changes the label otherwise.
+	Label func(traceData unsafe.Pointer) string
+}
+
+// RegisterTracebackHooks registers the hooks used by the runtime
+// to label goroutine tracebacks.
+func RegisterTracebackHooks(h TracebackHooks) {
+	setTracebackHooks(h.Label)
+}
+
+// SetTracebackLabel sets the label printed in the traceback header of
+// the calling goroutine, as if returned by TracebackHooks.Label, such as
+// after the name of its span changes. It is kept until the goroutine's
+// trace data changes, and does nothing for a goroutine without trace data.
+func SetTracebackLabel(label string) {
+	setTracebackLabel(label)
+}
+
+// These are implemented in the runtime package.
+func setTracebackHooks(label func(unsafe.Pointer) string)
+func setTracebackLabel(label string)
@@ -0,0 +1,76 @@
+package hooks
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+func TestTracebackHooks(t *testing.T) {
+	RegisterTracebackHooks(TracebackHooks{
+		Label: func(traceData unsafe.Pointer) string {
+			return fmt.Sprintf("trace-%d \"x\"\n", *(*int)(traceData))
+		},
+	})
+	defer RegisterTracebackHooks(TracebackHooks{})
+	RegisterGoroutineHooks(GoroutineHooks{
+		Start: func(pc uintptr, goRoutineID uint64, parentTraceData unsafe.Pointer) unsafe.Pointer {
+			return parentTraceData
+		},
+	})
+	defer RegisterGoroutineHooks(GoroutineHooks{})
+
+	data := 42
+	SetTraceData(unsafe.Pointer(&data))
+	ready := make(chan uint64)
+	release := make(chan struct{})
+	go func() {
+		defer SetTraceData(nil)
+		ready <- GoroutineID()
+		<-release
+	}()
+	SetTraceData(nil)
+	id := <-ready
+	defer close(release)
+
+	buf := make([]byte, 1<<20)
+	stacks := string(buf[:runtime.Stack(buf, true)])
+	want := fmt.Sprintf("goroutine %d [chan receive] \"trace-42 \\\"x\\\"\\n\":\n", id)
+	if !strings.Contains(stacks, want) {
+		t.Errorf("stacks do not contain %q:\n%s", want, stacks)
+	}
+	if me := fmt.Sprintf("goroutine %d [running]:\n", GoroutineID()); !strings.Contains(stacks, me) {
+		t.Errorf("stacks do not contain %q:\n%s", me, stacks)
+	}
+}
+
+func TestSetTracebackLabel(t *testing.T) {
+	RegisterTracebackHooks(TracebackHooks{
+		Label: func(traceData unsafe.Pointer) string { return "from-hook" },
+	})
+	defer RegisterTracebackHooks(TracebackHooks{})
+
+	header := func() string {
+		buf := make([]byte, 1<<10)
+		stack := string(buf[:runtime.Stack(buf, false)])
+		header, _, _ := strings.Cut(stack, "\n")
+		return header
+	}
+	data := 1
+	SetTraceData(unsafe.Pointer(&data))
+	defer SetTraceData(nil)
+	if got, want := header(), "[running] \"from-hook\":"; !strings.HasSuffix(got, want) {
+		t.Errorf("header with trace data is %q, want suffix %q", got, want)
+	}
+	SetTracebackLabel("renamed")
+	if got, want := header(), "[running] \"renamed\":"; !strings.HasSuffix(got, want) {
+		t.Errorf("header after SetTracebackLabel is %q, want suffix %q", got, want)
+	}
+	SetTraceData(nil)
+	SetTracebackLabel("ignored")
+	if got, want := header(), "[running]:"; !strings.HasSuffix(got, want) {
+		t.Errorf("header without trace data is %q, want suffix %q", got, want)
+	}
+}
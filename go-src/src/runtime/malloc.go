@@ -1161,6 +1161,10 @@ func mallocgc(size uintptr, typ *_type, needzero bool) unsafe.Pointer {
 		}
 	}
 
+	if tracingAllocRate != 0 {
+		tracingAlloc(size)
+	}
+
 	if assistG != nil {
 		// Account for internal fragmentation in the assist
 		// debt now that we know it.
@@ -1214,7 +1218,11 @@ func deductAssistCredit(size uintptr) *g {
 			// This G is in debt. Assist the GC to correct
 			// this before allocating. This must happen
 			// before disabling preemption.
+			start := tracingGCAssistStart(assistG)
 			gcAssistAlloc(assistG)
+			if start != 0 {
+				tracingGCAssist(assistG, start)
+			}
 		}
 	}
 	return assistG
@@ -735,6 +735,7 @@ func gcStart(trigger gcTrigger) {
 		// Release the CPU limiter.
 		gcCPULimiter.finishGCTransition(now)
 	})
+	pauseStart, pauseEnd := work.pauseStart, now
 
 	// Release the world sema before Gosched() in STW mode
 	// because we will need to reacquire it later but before
@@ -750,6 +751,7 @@ func gcStart(trigger gcTrigger) {
 	}
 
 	semrelease(&work.startSema)
+	tracingGCPause(pauseStart, pauseEnd)
 }
 
 // gcMarkDoneFlushed counts the number of P's with flushed work.
@@ -878,12 +880,15 @@ top:
 	})
 	if restart {
 		getg().m.preemptoff = ""
+		var pauseStart, pauseEnd int64
 		systemstack(func() {
 			now := startTheWorldWithSema(trace.enabled)
 			work.pauseNS += now - work.pauseStart
 			memstats.gcPauseDist.record(now - work.pauseStart)
+			pauseStart, pauseEnd = work.pauseStart, now
 		})
 		semrelease(&worldsema)
+		tracingGCPause(pauseStart, pauseEnd)
 		goto top
 	}
 
@@ -998,6 +1003,7 @@ func gcMarkTermination() {
 	work.pauseNS += now - work.pauseStart
 	work.tEnd = now
 	memstats.gcPauseDist.record(now - work.pauseStart)
+	pauseStart := work.pauseStart
 	atomic.Store64(&memstats.last_gc_unix, uint64(unixNow)) // must be Unix time to make sense to user
 	atomic.Store64(&memstats.last_gc_nanotime, uint64(now)) // monotonic time for us
 	memstats.pause_ns[memstats.numgc%uint32(len(memstats.pause_ns))] = uint64(work.pauseNS)
@@ -1178,6 +1184,8 @@ func gcMarkTermination() {
 	releasem(mp)
 	mp = nil
 
+	tracingGCPause(pauseStart, now)
+
 	// now that gc is done, kick off finalizer thread if needed
 	if !concurrentSweep {
 		// give the queued finalizers, if any, a chance to run
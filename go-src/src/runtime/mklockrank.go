@@ -51,6 +51,7 @@ NONE <
   sweepWaiters,
   assistQueue,
   sweep;
+NONE < tracingGCPause;
 
 # Scheduler, timers, netpoll
 NONE < pollDesc, cpuprof;
@@ -60,7 +61,8 @@ assistQueue,
   pollDesc, # pollDesc can interact with timers, which can lock sched.
   scavenge,
   sweep,
-  sweepWaiters
+  sweepWaiters,
+  tracingGCPause
 < sched;
 sched < allg, allp;
 allp < timers;
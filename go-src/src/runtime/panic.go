@@ -832,6 +832,8 @@ func gopanic(e any) {
 		throw("panic holding locks")
 	}
 
+	tracingPanic(gp, e)
+
 	var p _panic
 	p.arg = e
 	p.link = gp._panic
@@ -909,6 +911,7 @@ func gopanic(e any) {
 		}
 		if p.recovered {
 			gp._panic = p.link
+			tracingRecover(p.arg)
 			if gp._panic != nil && gp._panic.goexit && gp._panic.aborted {
 				// A normal recover would bypass/abort the Goexit.  Instead,
 				// we return to the processing loop of the Goexit.
@@ -1059,9 +1062,13 @@ func throw(s string) {
 func fatal(s string) {
 	// Everything fatal does should be recursively nosplit so it
 	// can be called even when it's unsafe to grow the stack.
+	crashHook := tracingCrashStart()
 	systemstack(func() {
 		print("fatal error: ", s, "\n")
 	})
+	if crashHook {
+		tracingCrash(nil, s)
+	}
 
 	fatalthrow(throwTypeUser)
 }
@@ -1094,6 +1101,8 @@ func recovery(gp *g) {
 	// Make the deferproc for this d return again,
 	// this time returning 1. The calling function will
 	// jump to the standard return epilogue.
+	tracingUnwind(gp, sp)
+
 	gp.sched.sp = sp
 	gp.sched.pc = pc
 	gp.sched.lr = 0
@@ -1142,6 +1151,17 @@ func fatalpanic(msgs *_panic) {
 	pc := getcallerpc()
 	sp := getcallersp()
 	gp := getg()
+	// If there is a crash hook, print the panic messages before calling
+	// it, rather than under paniclk as usual, as the hook runs ordinary
+	// Go code, which startpanic_m forbids.
+	printed := false
+	if msgs != nil && tracingCrashStart() {
+		systemstack(func() {
+			printpanics(msgs)
+		})
+		printed = true
+		tracingCrash(msgs.arg, "")
+	}
 	var docrash bool
 	// Switch to the system stack to avoid any stack growth, which
 	// may make things worse if the runtime is in a bad state.
@@ -1155,7 +1175,9 @@ func fatalpanic(msgs *_panic) {
 			// decrement runningPanicDefers.
 			runningPanicDefers.Add(-1)
 
-			printpanics(msgs)
+			if !printed {
+				printpanics(msgs)
+			}
 		}
 
 		docrash = dopanic_m(gp, pc, sp)
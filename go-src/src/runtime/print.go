@@ -246,6 +246,37 @@ func printstring(s string) {
 	gwrite(bytes(s))
 }
 
+// printquoted prints s in double quotes, escaping quotes, backslashes
+// and control characters as in a Go string literal, so that strings
+// from user code, such as goroutine names, can't forge traceback lines.
+func printquoted(s string) {
+	const dig = "0123456789abcdef"
+	printstring("\"")
+	start := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c >= ' ' && c != '"' && c != '\\' && c != 0x7f {
+			continue
+		}
+		printstring(s[start:i])
+		start = i + 1
+		switch c {
+		case '"', '\\':
+			buf := [2]byte{'\\', c}
+			gwrite(buf[:])
+		case '\n':
+			printstring("\\n")
+		case '\t':
+			printstring("\\t")
+		default:
+			buf := [4]byte{'\\', 'x', dig[c>>4], dig[c&0xf]}
+			gwrite(buf[:])
+		}
+	}
+	printstring(s[start:])
+	printstring("\"")
+}
+
 func printslice(s []byte) {
 	sp := (*slice)(unsafe.Pointer(&s))
 	print("[", len(s), "/", cap(s), "]")
@@ -365,6 +365,7 @@ func gopark(unlockf func(*g, unsafe.Pointer) bool, lock unsafe.Pointer, reason w
 	if reason != waitReasonSleep {
 		checkTimeouts() // timeouts may expire while two goroutines keep the scheduler busy
 	}
+	tracingPark(getg(), reason)
 	mp := acquirem()
 	gp := mp.curg
 	status := readgstatus(gp)
@@ -436,6 +437,9 @@ func releaseSudog(s *sudog) {
 	if s.elem != nil {
 		throw("runtime: sudog with non-nil elem")
 	}
+	if s.traceData != nil {
+		throw("runtime: sudog with non-nil traceData")
+	}
 	if s.isSelect {
 		throw("runtime: sudog with non-false isSelect")
 	}
@@ -727,6 +731,7 @@ func schedinit() {
 	goargs()
 	goenvs()
 	parsedebugvars()
+	schedrand = uint64(uint32(debug.schedseed))
 	gcinit()
 
 	// if disableMemoryProfiling is set, update MemProfileRate to 0 to turn off memprofile.
@@ -885,8 +890,13 @@ func ready(gp *g, traceskip int, next bool) {
 	}
 
 	// status is Gwaiting or Gscanwaiting, make Grunnable and put on runq
+	tracingReady(gp)
 	casgstatus(gp, _Gwaiting, _Grunnable)
-	runqput(mp.p.ptr(), gp, next)
+	if !next && gp.schedClass == schedClassHigh {
+		runqputhigh(mp.p.ptr(), gp)
+	} else {
+		runqput(mp.p.ptr(), gp, next)
+	}
 	wakep()
 	releasem(mp)
 }
@@ -2646,6 +2656,7 @@ func execute(gp *g, inheritTime bool) {
 	if !inheritTime {
 		mp.p.ptr().schedtick++
 	}
+	tracingRun(gp)
 
 	// Check whether the profiler needs to be turned on or off.
 	hz := sched.profilehz
@@ -2714,7 +2725,8 @@ top:
 	// Check the global runnable queue once in a while to ensure fairness.
 	// Otherwise two goroutines can completely occupy the local runqueue
 	// by constantly respawning each other.
-	if pp.schedtick%61 == 0 && sched.runqsize > 0 {
+	// With GODEBUG=schedseed, which queue to take from is left to chance.
+	if sched.runqsize > 0 && (pp.schedtick%61 == 0 || debug.schedseed != 0 && schedrandn(2) == 0) {
 		lock(&sched.lock)
 		gp := globrunqget(pp, 1)
 		unlock(&sched.lock)
@@ -2733,6 +2745,16 @@ top:
 		asmcgocall(*cgo_yield, nil)
 	}
 
+	// high class goroutines waiting on the global queue
+	if sched.runqhighsize != 0 {
+		lock(&sched.lock)
+		gp := globrunqget(pp, 1)
+		unlock(&sched.lock)
+		if gp != nil {
+			return gp, false, false
+		}
+	}
+
 	// local runq
 	if gp, inheritTime := runqget(pp); gp != nil {
 		return gp, inheritTime, false
@@ -2748,6 +2770,11 @@ top:
 		}
 	}
 
+	// local background goroutines, see runqget
+	if gp := pp.runqbg.get(); gp != nil {
+		return gp, false, false
+	}
+
 	// Poll network.
 	// This netpoll is only an optimization before we resort to stealing.
 	// We can safely skip it if there are no waiters or a thread is blocked
@@ -3270,6 +3297,7 @@ func injectglist(glist *gList) {
 	for gp := head; gp != nil; gp = gp.schedlink.ptr() {
 		tail = gp
 		qsize++
+		tracingReady(gp)
 		casgstatus(gp, _Gwaiting, _Grunnable)
 	}
 
@@ -3490,6 +3518,7 @@ func park_m(gp *g) {
 	if trace.enabled {
 		traceGoPark(mp.waittraceev, mp.waittraceskip)
 	}
+	tracingStop(gp, false)
 
 	// N.B. Not using casGToWaiting here because the waitreason is
 	// set by park_m's caller.
@@ -3504,6 +3533,7 @@ func park_m(gp *g) {
 			if trace.enabled {
 				traceGoUnpark(gp, 2)
 			}
+			tracingReady(gp)
 			casgstatus(gp, _Gwaiting, _Grunnable)
 			execute(gp, true) // Schedule it back, never returns.
 		}
@@ -3517,6 +3547,7 @@ func goschedImpl(gp *g) {
 		dumpgstatus(gp)
 		throw("bad g status")
 	}
+	tracingStop(gp, true)
 	casgstatus(gp, _Grunning, _Grunnable)
 	dropg()
 	lock(&sched.lock)
@@ -3581,6 +3612,8 @@ func preemptPark(gp *g) {
 		}
 	}
 
+	tracingStop(gp, false)
+
 	// Transition from _Grunning to _Gscan|_Gpreempted. We can't
 	// be in _Grunning when we dropg because then we'd be running
 	// without an M, but the moment we're in _Gpreempted,
@@ -3606,6 +3639,7 @@ func goyield_m(gp *g) {
 		traceGoPreempt()
 	}
 	pp := gp.m.p.ptr()
+	tracingStop(gp, true)
 	casgstatus(gp, _Grunning, _Grunnable)
 	dropg()
 	runqput(pp, gp, false)
@@ -3614,6 +3648,14 @@ func goyield_m(gp *g) {
 
 // Finishes execution of the current goroutine.
 func goexit1() {
+	// If we have per-goroutine data, then we need to call the cleanup
+	// hooks to let the owning libraries know it's exiting. This is done
+	// before switching to the system stack, for the same reasons as the
+	// inherit hooks in newproc.
+	gp := getg()
+	tracingFlushAlloc(gp)
+	gdataCleanup(gp)
+
 	if raceenabled {
 		racegoend()
 	}
@@ -3628,12 +3670,6 @@ func goexit0(gp *g) {
 	mp := getg().m
 	pp := mp.p.ptr()
 
-	// If we have trace data, then we need to call the exit hook.
-	// to let our library know it's exiting
-	if traceData := gp.traceData; traceData != nil {
-		tracingGExit(gp.goid, traceData)
-	}
-
 	casgstatus(gp, _Grunning, _Gdead)
 	gcController.addScannableStack(pp, -int64(gp.stack.hi-gp.stack.lo))
 	if isSystemGoroutine(gp, false) {
@@ -3645,12 +3681,24 @@ func goexit0(gp *g) {
 	mp.lockedg = 0
 	gp.preemptStop = false
 	gp.paniconfault = false
+	gp.panicHookSP = 0
 	gp._defer = nil // should be true already but just in case.
 	gp._panic = nil // non-nil for Goexit during panic. points at stack-allocated data.
 	gp.writebuf = nil
 	gp.waitreason = waitReasonZero
 	gp.param = nil
 	gp.labels = nil
+	gp.createStack = nil
+	gp.traceNewg = nil
+	gp.traceSyscallStart = 0
+	gp.traceInCgo = false
+	gp.schedClass = schedClassNormal
+	if root := gp.traceRoot; root != nil {
+		root.live.Add(-1)
+		gp.traceRoot = nil
+	}
+	gp.name = nil
+	gp.nameOwn = false
 	gp.timer = nil
 
 	if gcBlackenEnabled != 0 && gp.gcAssistBytes > 0 {
@@ -3768,6 +3816,7 @@ func reentersyscall(pc, sp uintptr) {
 	// Disable preemption because during this function g is in Gsyscall status,
 	// but can have inconsistent g->sched, do not let GC observe it.
 	gp.m.locks++
+	tracingEnterSyscall(gp)
 
 	// Entersyscall must not call any function that might split/grow the stack.
 	// (See details in comment above.)
@@ -3868,6 +3917,7 @@ func entersyscallblock() {
 	gp.m.locks++ // see comment in entersyscall
 	gp.throwsplit = true
 	gp.stackguard0 = stackPreempt // see comment in entersyscall
+	tracingEnterSyscall(gp)
 	gp.m.syscalltick = gp.m.p.ptr().syscalltick
 	gp.sysblocktraced = true
 	gp.m.p.ptr().syscalltick++
@@ -3968,6 +4018,10 @@ func exitsyscall() {
 		}
 		gp.throwsplit = false
 
+		if gp.traceSyscallStart > 0 {
+			tracingExitSyscall(gp)
+		}
+
 		if sched.disable.user && !schedEnabled(gp) {
 			// Scheduling of this goroutine is disabled.
 			Gosched()
@@ -4004,6 +4058,10 @@ func exitsyscall() {
 	gp.syscallsp = 0
 	gp.m.p.ptr().syscalltick++
 	gp.throwsplit = false
+
+	if gp.traceSyscallStart > 0 {
+		tracingExitSyscall(gp)
+	}
 }
 
 //go:nosplit
@@ -4091,6 +4149,9 @@ func exitsyscallfast_pidle() bool {
 //
 //go:nowritebarrierrec
 func exitsyscall0(gp *g) {
+	// gp is leaving its system call to wait for a P,
+	// after which execute runs it again.
+	tracingStopSyscall(gp)
 	casgstatus(gp, _Gsyscall, _Grunnable)
 	dropg()
 	lock(&sched.lock)
@@ -4247,9 +4308,24 @@ func malg(stacksize int32) *g {
 func newproc(fn *funcval) {
 	gp := getg()
 	pc := getcallerpc()
+	createStack := tracingCreateStack(gp)
+	var newg *g
 	systemstack(func() {
-		newg := newproc1(fn, gp, pc)
+		newg = newproc1(fn, gp, pc)
+	})
 
+	// Call the inherit hooks for any per-goroutine data on the current
+	// goroutine. They run on our own stack, rather than the system stack,
+	// so they're free to allocate and grow the stack, and before newg is
+	// queued so it never runs without its data. Runtime goroutines, such
+	// as the GC workers, inherit nothing, as they don't work on behalf of
+	// whichever goroutine happened to start them.
+	if !isSystemGoroutine(newg, false) {
+		newg.createStack = createStack
+		gdataInherit(newg, gp, fn.fn)
+	}
+
+	systemstack(func() {
 		pp := getg().m.p.ptr()
 		runqput(pp, newg, true)
 
@@ -4300,6 +4376,7 @@ func newproc1(fn *funcval, callergp *g, callerpc uintptr) *g {
 	newg.sched.pc = abi.FuncPCABI0(goexit) + sys.PCQuantum // +PCQuantum so that previous instruction is in same function
 	newg.sched.g = guintptr(unsafe.Pointer(newg))
 	gostartcallfn(&newg.sched, fn)
+	newg.parentGoid = callergp.goid
 	newg.gopc = callerpc
 	newg.ancestors = saveAncestors(callergp)
 	newg.startpc = fn.fn
@@ -4310,6 +4387,13 @@ func newproc1(fn *funcval, callergp *g, callerpc uintptr) *g {
 		if mp.curg != nil {
 			newg.labels = mp.curg.labels
 		}
+		// They also join their creator's trace root.
+		if root := callergp.traceRoot; root != nil {
+			newg.traceRoot = root
+			root.live.Add(1)
+		}
+		// And their name, as the prefix of any name they set.
+		newg.name = callergp.name
 		if goroutineProfile.active {
 			// A concurrent goroutine profile is running. It should include
 			// exactly the set of goroutines that were alive when the goroutine
@@ -4349,12 +4433,6 @@ func newproc1(fn *funcval, callergp *g, callerpc uintptr) *g {
 		traceGoCreate(newg, newg.startpc)
 	}
 
-	// Call our trace goroutine start hook if we have trace
-	// data on the current goroutine.
-	if traceData := callergp.traceData; callergp != nil {
-		newg.traceData = tracingGStart(fn.fn, newg.goid, traceData)
-	}
-
 	releasem(mp)
 
 	return newg
@@ -4881,6 +4959,12 @@ func (pp *p) destroy() {
 		globrunqputhead(pp.runnext.ptr())
 		pp.runnext = 0
 	}
+	for gp := pp.runqhigh.get(); gp != nil; gp = pp.runqhigh.get() {
+		globrunqputhigh(gp)
+	}
+	for gp := pp.runqbg.get(); gp != nil; gp = pp.runqbg.get() {
+		globrunqput(gp)
+	}
 	if len(pp.timers) > 0 {
 		plocal := getg().m.p.ptr()
 		// The world is stopped, but we acquire timersLock to
@@ -5168,6 +5252,12 @@ func incidlelocked(v int32) {
 	unlock(&sched.lock)
 }
 
+// The fatal errors reported by checkdead.
+const (
+	deadlockError       = "all goroutines are asleep - deadlock!"
+	deadlockGoexitError = "no goroutines (main called runtime.Goexit) - deadlock!"
+)
+
 // Check for deadlock situation.
 // The check is based on number of running M's, if 0 -> deadlock.
 // sched.lock must be held.
@@ -5230,8 +5320,11 @@ func checkdead() {
 		}
 	})
 	if grunning == 0 { // possible if main goroutine calls runtime·Goexit()
+		if tracingDeadlock(true) {
+			return
+		}
 		unlock(&sched.lock) // unlock so that GODEBUG=scheddetail=1 doesn't hang
-		fatal("no goroutines (main called runtime.Goexit) - deadlock!")
+		fatal(deadlockGoexitError)
 	}
 
 	// Maybe jump time forward for playground.
@@ -5270,8 +5363,11 @@ func checkdead() {
 		}
 	}
 
+	if tracingDeadlock(false) {
+		return
+	}
 	unlock(&sched.lock) // unlock so that GODEBUG=scheddetail=1 doesn't hang
-	fatal("all goroutines are asleep - deadlock!")
+	fatal(deadlockError)
 }
 
 // forcegcperiod is the maximum time in nanoseconds between garbage
@@ -5749,6 +5845,45 @@ func globrunqputhead(gp *g) {
 	sched.runqsize++
 }
 
+// runqputhigh puts gp, of the high class, on the runqhigh queue of pp,
+// to run ahead of the goroutines in its runq, without inheriting the
+// remaining time slice. If the queue is full, it puts gp on the global
+// queue of high class goroutines, which runs ahead of the global and
+// local runnable queues. It is used instead of runqput where a high
+// class goroutine is made runnable but cannot go in runnext, but not as
+// it yields.
+// Executed only by the owner P.
+func runqputhigh(pp *p, gp *g) {
+	if pp.runqhigh.put(gp) {
+		return
+	}
+	lock(&sched.lock)
+	globrunqputhigh(gp)
+	unlock(&sched.lock)
+}
+
+// globrunqputhigh puts gp on the global queue of high class goroutines.
+// sched.lock must be held.
+func globrunqputhigh(gp *g) {
+	assertLockHeld(&sched.lock)
+
+	sched.runqhigh.pushBack(gp)
+	sched.runqhighsize++
+	sched.runqsize++
+}
+
+// runqputbg puts gp, of the background class, on the runqbg queue of pp,
+// or if it is full, on the global runnable queue.
+// Executed only by the owner P.
+func runqputbg(pp *p, gp *g) {
+	if pp.runqbg.put(gp) {
+		return
+	}
+	lock(&sched.lock)
+	globrunqput(gp)
+	unlock(&sched.lock)
+}
+
 // Put a batch of runnable goroutines on the global runnable queue.
 // This clears *batch.
 // sched.lock must be held.
@@ -5772,6 +5907,13 @@ func globrunqget(pp *p, max int32) *g {
 		return nil
 	}
 
+	// High class goroutines are taken first, one at a time.
+	if sched.runqhighsize != 0 {
+		sched.runqhighsize--
+		sched.runqsize--
+		return sched.runqhigh.pop()
+	}
+
 	n := sched.runqsize/gomaxprocs + 1
 	if n > sched.runqsize {
 		n = sched.runqsize
@@ -5789,6 +5931,13 @@ func globrunqget(pp *p, max int32) *g {
 	n--
 	for ; n > 0; n-- {
 		gp1 := sched.runq.pop()
+		if gp1.schedClass == schedClassBackground {
+			// Not runqput, which takes sched.lock if runqbg is full.
+			if !pp.runqbg.put(gp1) {
+				globrunqput(gp1)
+			}
+			continue
+		}
 		runqput(pp, gp1, false)
 	}
 	return gp
@@ -5939,7 +6088,7 @@ func pidlegetSpinning(now int64) (*p, int64) {
 	return pp, now
 }
 
-// runqempty reports whether pp has no Gs on its local run queue.
+// runqempty reports whether pp has no Gs on its local run queues.
 // It never returns true spuriously.
 func runqempty(pp *p) bool {
 	// Defend against a race where 1) pp has G1 in runqnext but runqhead == runqtail,
@@ -5951,11 +6100,56 @@ func runqempty(pp *p) bool {
 		tail := atomic.Load(&pp.runqtail)
 		runnext := atomic.Loaduintptr((*uintptr)(unsafe.Pointer(&pp.runnext)))
 		if tail == atomic.Load(&pp.runqtail) {
-			return head == tail && runnext == 0
+			// Goroutines only move between runnext and runq, so
+			// the class queues can be checked last.
+			return head == tail && runnext == 0 && pp.runqhigh.empty() && pp.runqbg.empty()
+		}
+	}
+}
+
+// A classRunq is a queue of runnable goroutines of one scheduling class
+// on a P, see schedClassHigh. Like the P's runq, it is accessed without
+// lock: only the owner P puts goroutines in it, and any P may take them.
+type classRunq struct {
+	head uint32
+	tail uint32
+	q    [64]guintptr
+}
+
+// put adds gp to the tail of q, reporting whether there was room for it.
+// Executed only by the owner P.
+func (q *classRunq) put(gp *g) bool {
+	h := atomic.LoadAcq(&q.head) // load-acquire, synchronize with consumers
+	t := q.tail
+	if t-h >= uint32(len(q.q)) {
+		return false
+	}
+	q.q[t%uint32(len(q.q))].set(gp)
+	atomic.StoreRel(&q.tail, t+1) // store-release, makes the item available for consumption
+	return true
+}
+
+// get removes and returns the g at the head of q, or nil if q is empty.
+// Can be executed by any P.
+func (q *classRunq) get() *g {
+	for {
+		h := atomic.LoadAcq(&q.head) // load-acquire, synchronize with other consumers
+		t := atomic.LoadAcq(&q.tail) // load-acquire, synchronize with the producer
+		if t == h {
+			return nil
+		}
+		gp := q.q[h%uint32(len(q.q))].ptr()
+		if atomic.CasRel(&q.head, h, h+1) { // cas-release, commits consume
+			return gp
 		}
 	}
 }
 
+// empty reports whether q has no Gs.
+func (q *classRunq) empty() bool {
+	return atomic.Load(&q.head) == atomic.Load(&q.tail)
+}
+
 // To shake out latent assumptions about scheduling order,
 // we introduce some randomness into scheduling decisions
 // when running with the race detector.
@@ -5973,21 +6167,36 @@ const randomizeScheduler = raceenabled
 // If the run queue is full, runnext puts g on the global queue.
 // Executed only by the owner P.
 func runqput(pp *p, gp *g, next bool) {
-	if randomizeScheduler && next && fastrandn(2) == 0 {
+	if (randomizeScheduler || debug.schedseed != 0) && next && schedrandn(2) == 0 {
 		next = false
 	}
 
+	// A background goroutine never goes in runnext, nor runq.
+	if gp.schedClass == schedClassBackground {
+		runqputbg(pp, gp)
+		return
+	}
+
 	if next {
 	retryNext:
 		oldnext := pp.runnext
+		if oldnext != 0 && oldnext.ptr().schedClass == schedClassHigh && gp.schedClass != schedClassHigh {
+			// Don't kick a high class goroutine out for a lower one.
+			goto retry
+		}
 		if !pp.runnext.cas(oldnext, guintptr(unsafe.Pointer(gp))) {
 			goto retryNext
 		}
 		if oldnext == 0 {
 			return
 		}
-		// Kick the old runnext out to the regular run queue.
+		// Kick the old runnext out to the regular run queue,
+		// or if it is of the high class, ahead of it.
 		gp = oldnext.ptr()
+		if gp.schedClass == schedClassHigh {
+			runqputhigh(pp, gp)
+			return
+		}
 	}
 
 retry:
@@ -6056,6 +6265,10 @@ func runqputbatch(pp *p, q *gQueue, qsize int) {
 	n := uint32(0)
 	for !q.empty() && t-h < uint32(len(pp.runq)) {
 		gp := q.pop()
+		if gp.schedClass == schedClassBackground && pp.runqbg.put(gp) {
+			qsize--
+			continue
+		}
 		pp.runq[t%uint32(len(pp.runq))].set(gp)
 		t++
 		n++
@@ -6085,6 +6298,13 @@ func runqputbatch(pp *p, q *gQueue, qsize int) {
 // current time slice. Otherwise, it should start a new time slice.
 // Executed only by the owner P.
 func runqget(pp *p) (gp *g, inheritTime bool) {
+	if debug.schedseed != 0 && gomaxprocs == 1 {
+		if gp, inheritTime := runqgetseeded(pp); gp != nil {
+			return gp, inheritTime
+		}
+		return pp.runqhigh.get(), false
+	}
+
 	// If there's a runnext, it's the next G to run.
 	next := pp.runnext
 	// If the runnext is non-0 and the CAS fails, it could only have been stolen by another P,
@@ -6094,6 +6314,20 @@ func runqget(pp *p) (gp *g, inheritTime bool) {
 		return next.ptr(), true
 	}
 
+	// High class goroutines run ahead of runq. Background goroutines
+	// are left to findRunnable, which runs them once the local and
+	// global runnable queues are empty, except every 61st schedule, so
+	// that they still run on a busy P. That schedule is offset from
+	// findRunnable's check of the global queue, so that a busy global
+	// queue doesn't take its turn.
+	if gp := pp.runqhigh.get(); gp != nil {
+		return gp, false
+	}
+	if pp.schedtick%61 == 30 {
+		if gp := pp.runqbg.get(); gp != nil {
+			return gp, false
+		}
+	}
 	for {
 		h := atomic.LoadAcq(&pp.runqhead) // load-acquire, synchronize with other consumers
 		t := pp.runqtail
@@ -6107,9 +6341,47 @@ func runqget(pp *p) (gp *g, inheritTime bool) {
 	}
 }
 
+// runqgetseeded gets a pseudo-randomly chosen g from pp's local runnable
+// queue, including runnext, for GODEBUG=schedseed. It relies on pp being
+// the only P, so that no other P can steal from its queue concurrently.
+// inheritTime is true if gp should inherit the remaining time in the
+// current time slice.
+// Executed only by the owner P.
+func runqgetseeded(pp *p) (gp *g, inheritTime bool) {
+	h := pp.runqhead
+	t := pp.runqtail
+	n := t - h
+	if pp.runnext != 0 {
+		n++
+	}
+	if n == 0 {
+		return nil, false
+	}
+	i := schedrandn(n)
+	if pp.runnext != 0 {
+		if i == 0 {
+			gp = pp.runnext.ptr()
+			pp.runnext = 0
+			return gp, true
+		}
+		i--
+	}
+	// Swap the chosen g to the head of the queue and take it from there.
+	a, b := h%uint32(len(pp.runq)), (h+i)%uint32(len(pp.runq))
+	pp.runq[a], pp.runq[b] = pp.runq[b], pp.runq[a]
+	gp = pp.runq[a].ptr()
+	atomic.StoreRel(&pp.runqhead, h+1)
+	return gp, false
+}
+
 // runqdrain drains the local runnable queue of pp and returns all goroutines in it.
 // Executed only by the owner P.
 func runqdrain(pp *p) (drainQ gQueue, n uint32) {
+	for gp := pp.runqhigh.get(); gp != nil; gp = pp.runqhigh.get() {
+		drainQ.pushBack(gp)
+		n++
+	}
+
 	oldNext := pp.runnext
 	if oldNext != 0 && pp.runnext.cas(oldNext, 0) {
 		drainQ.pushBack(oldNext.ptr())
@@ -6120,26 +6392,30 @@ retry:
 	h := atomic.LoadAcq(&pp.runqhead) // load-acquire, synchronize with other consumers
 	t := pp.runqtail
 	qn := t - h
-	if qn == 0 {
-		return
-	}
 	if qn > uint32(len(pp.runq)) { // read inconsistent h and t
 		goto retry
 	}
 
-	if !atomic.CasRel(&pp.runqhead, h, h+qn) { // cas-release, commits consume
-		goto retry
+	if qn != 0 {
+		if !atomic.CasRel(&pp.runqhead, h, h+qn) { // cas-release, commits consume
+			goto retry
+		}
+
+		// We've inverted the order in which it gets G's from the local P's runnable queue
+		// and then advances the head pointer because we don't want to mess up the statuses of G's
+		// while runqdrain() and runqsteal() are running in parallel.
+		// Thus we should advance the head pointer before draining the local P into a gQueue,
+		// so that we can update any gp.schedlink only after we take the full ownership of G,
+		// meanwhile, other P's can't access to all G's in local P's runnable queue and steal them.
+		// See https://groups.google.com/g/golang-dev/c/0pTKxEKhHSc/m/6Q85QjdVBQAJ for more details.
+		for i := uint32(0); i < qn; i++ {
+			gp := pp.runq[(h+i)%uint32(len(pp.runq))].ptr()
+			drainQ.pushBack(gp)
+			n++
+		}
 	}
 
-	// We've inverted the order in which it gets G's from the local P's runnable queue
-	// and then advances the head pointer because we don't want to mess up the statuses of G's
-	// while runqdrain() and runqsteal() are running in parallel.
-	// Thus we should advance the head pointer before draining the local P into a gQueue,
-	// so that we can update any gp.schedlink only after we take the full ownership of G,
-	// meanwhile, other P's can't access to all G's in local P's runnable queue and steal them.
-	// See https://groups.google.com/g/golang-dev/c/0pTKxEKhHSc/m/6Q85QjdVBQAJ for more details.
-	for i := uint32(0); i < qn; i++ {
-		gp := pp.runq[(h+i)%uint32(len(pp.runq))].ptr()
+	for gp := pp.runqbg.get(); gp != nil; gp = pp.runqbg.get() {
 		drainQ.pushBack(gp)
 		n++
 	}
@@ -6206,10 +6482,15 @@ func runqgrab(pp *p, batch *[256]guintptr, batchHead uint32, stealRunNextG bool)
 // and put onto local runnable queue of p.
 // Returns one of the stolen elements (or nil if failed).
 func runqsteal(pp, p2 *p, stealRunNextG bool) *g {
+	// Take high class goroutines first, and background ones last, one
+	// at a time, to run them right away.
+	if gp := p2.runqhigh.get(); gp != nil {
+		return gp
+	}
 	t := pp.runqtail
 	n := runqgrab(p2, &pp.runq, t, stealRunNextG)
 	if n == 0 {
-		return nil
+		return p2.runqbg.get()
 	}
 	n--
 	gp := pp.runq[(t+n)%uint32(len(pp.runq))].ptr()
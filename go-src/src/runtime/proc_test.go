@@ -1155,3 +1155,22 @@ func TestBigGOMAXPROCS(t *testing.T) {
 		t.Errorf("output:\n%s\nwanted:\nunknown function: NonexistentTest", output)
 	}
 }
+
+func TestSchedSeed(t *testing.T) {
+	run := func(seed int) string {
+		output := runTestProg(t, "testprog", "SchedSeed", "GOMAXPROCS=1", fmt.Sprintf("GODEBUG=schedseed=%d", seed))
+		order, _, _ := strings.Cut(output, "\n")
+		return order
+	}
+	orders := make(map[string]bool)
+	for seed := 1; seed <= 5; seed++ {
+		order := run(seed)
+		if again := run(seed); again != order {
+			t.Errorf("schedseed=%d: goroutines ran in order %q, then %q", seed, order, again)
+		}
+		orders[order] = true
+	}
+	if len(orders) < 2 {
+		t.Errorf("goroutines ran in the same order with every seed: %v", orders)
+	}
+}
@@ -38,3 +38,21 @@ func runtime_setProfLabel(labels unsafe.Pointer) {
 func runtime_getProfLabel() unsafe.Pointer {
 	return getg().labels
 }
+
+// hooks_setProfLabel is called by runtime/hooks/internal/labels.Set as
+// the hooks set the profiler labels of a HTTP handler or SQL query, or
+// the name of a goroutine.
+//
+//go:linkname hooks_setProfLabel runtime/hooks/internal/labels.Set
+func hooks_setProfLabel(labels unsafe.Pointer) {
+	runtime_setProfLabel(labels)
+}
+
+// hooks_getProfLabel is called by runtime/hooks/internal/labels.Get as
+// the hooks set the profiler labels of a HTTP handler or SQL query, or
+// the name of a goroutine.
+//
+//go:linkname hooks_getProfLabel runtime/hooks/internal/labels.Get
+func hooks_getProfLabel() unsafe.Pointer {
+	return getg().labels
+}
@@ -322,6 +322,8 @@ var debug struct {
 	asyncpreemptoff    int32
 	harddecommit       int32
 	adaptivestackstart int32
+	tracinghooks       int32
+	schedseed          int32
 
 	// debug.malloc is used as a combined debug check
 	// in the malloc function and should be set
@@ -353,6 +355,8 @@ var dbgvars = []dbgVar{
 	{"inittrace", &debug.inittrace},
 	{"harddecommit", &debug.harddecommit},
 	{"adaptivestackstart", &debug.adaptivestackstart},
+	{"tracinghooks", &debug.tracinghooks},
+	{"schedseed", &debug.schedseed},
 }
 
 var globalGODEBUG string
@@ -356,6 +356,10 @@ type sudog struct {
 	prev *sudog
 	elem unsafe.Pointer // data element (may point to stack)
 
+	// traceData is the context carried with elem by a traced send,
+	// or for semaphores the trace data of the goroutine which woke g.
+	traceData unsafe.Pointer
+
 	// The following fields are never accessed concurrently.
 	// For channels, waitlink is only accessed by g.
 	// For semaphores, all fields (including the ones above)
@@ -468,6 +472,10 @@ type g struct {
 	sysblocktraced bool     // StartTrace has emitted EvGoInSyscall about this goroutine
 	tracking       bool     // whether we're tracking this G for sched latency statistics
 	trackingSeq    uint8    // used to decide whether to track this G
+	traceInCgo     bool     // the timed system call is a cgo call, see tracingExitSyscall
+	schedClass     uint8    // scheduling class, see schedClassNormal
+	nameOwn        bool     // name was set on this goroutine, rather than inherited
+	traceFromTimer bool     // trace data was taken from a timer, without the start hook, see gdataInherit
 	trackingStamp  int64    // timestamp of when the G last started being tracked
 	runnableTime   int64    // the amount of time spent runnable, cleared when running, only used when tracking
 	sysexitticks   int64    // cputicks when syscall has returned (for tracing)
@@ -479,6 +487,7 @@ type g struct {
 	sigcode0       uintptr
 	sigcode1       uintptr
 	sigpc          uintptr
+	parentGoid     uint64          // goid of goroutine that created this goroutine
 	gopc           uintptr         // pc of go statement that created this goroutine
 	ancestors      *[]ancestorInfo // ancestor information goroutine(s) that created this goroutine (only used if debug.tracebackancestors)
 	startpc        uintptr         // pc of goroutine function
@@ -489,9 +498,54 @@ type g struct {
 	timer          *timer         // cached timer for time.Sleep
 	selectDone     atomic.Uint32  // are we participating in a select and did someone win the race?
 
-	// traceData is a pointer to a trace object
-	// that we will define later in our library code
-	traceData unsafe.Pointer
+	// gdata holds the per-goroutine data slots allocated through
+	// runtime/hooks, see gdata.go. Slot gdataTrace holds the trace
+	// object defined by the library which registered the goroutine hooks.
+	gdata [maxGData]unsafe.Pointer
+
+	// traceLabel is printed in the goroutine's traceback header, if it
+	// is still for its trace data. It is updated as the trace data is
+	// set, see tracingUpdateLabel.
+	traceLabel *tracingLabel
+
+	// traceAllocNext is the number of bytes the goroutine allocates
+	// before its next sampled allocation. traceAllocBytes, less
+	// traceAllocNext, and traceAllocObjects count the memory allocated
+	// by the goroutine with its current trace data, until they are
+	// reported to the alloc hook. See tracingAlloc.
+	traceAllocNext    uintptr
+	traceAllocBytes   uintptr
+	traceAllocObjects uintptr
+
+	// traceSyscallStart is the time the goroutine entered the system
+	// call it is in, if it is being timed, or -1 while the syscall hook
+	// runs. See tracingEnterSyscall.
+	traceSyscallStart int64
+
+	// traceStopTime is the time the goroutine stopped as it left a
+	// system call without a P, if the stop hook is still to be called
+	// for it. See tracingStopSyscall.
+	traceStopTime int64
+
+	// createStack is the stack of the goroutine which created this one,
+	// if it had trace data. See tracingCreateStack.
+	createStack []uintptr
+
+	// traceNewg is the goroutine this one is starting, while the inherit
+	// callbacks run for it. See gdataInherit.
+	traceNewg *g
+
+	// panicHookSP is the offset from stack.hi of the frame which called
+	// the panic hook, while it runs, or 0. See tracingPanic.
+	panicHookSP uintptr
+
+	// traceRoot is the trace root the goroutine joined,
+	// or inherited from its creator. See traceRoot.
+	traceRoot *traceRoot
+
+	// name is the goroutine's name, inherited from its creator
+	// unless nameOwn is set. See gName.
+	name *gName
 
 	// goroutineProfiled indicates the status of this goroutine's stack for the
 	// current in-progress goroutine profile
@@ -582,6 +636,10 @@ type m struct {
 	syscalltick   uint32
 	freelink      *m // on sched.freem
 
+	// timerTraceData is the trace data of the timer whose function
+	// this m is running, for goroutines the function starts.
+	timerTraceData unsafe.Pointer
+
 	// these are here because they are too large to be on the stack
 	// of low-level NOSPLIT functions.
 	libcall   libcall
@@ -647,6 +705,12 @@ type p struct {
 	// only the owner P can CAS it to a valid G.
 	runnext guintptr
 
+	// Queues of runnable goroutines of the high and background
+	// scheduling classes, which run before and after those in runq.
+	// See schedClassHigh.
+	runqhigh classRunq
+	runqbg   classRunq
+
 	// Available G's (status == Gdead)
 	gFree struct {
 		gList
@@ -796,6 +860,12 @@ type schedt struct {
 	runq     gQueue
 	runqsize int32
 
+	// High class goroutines which overflowed the runqhigh queue of
+	// their P, which run ahead of the global and local runnable queues.
+	// They are counted in runqsize.
+	runqhigh     gQueue
+	runqhighsize int32
+
 	// disable controls selective disabling of the scheduler.
 	//
 	// Use schedEnableUser to control this.
@@ -1088,6 +1158,8 @@ const (
 	waitReasonDebugCall                               // "debug call"
 	waitReasonGCMarkTermination                       // "GC mark termination"
 	waitReasonStoppingTheWorld                        // "stopping the world"
+	waitReasonDeadlockHookIdle                        // "deadlock hook (idle)"
+	waitReasonGCPauseHookIdle                         // "GC pause hook (idle)"
 )
 
 var waitReasonStrings = [...]string{
@@ -1123,6 +1195,8 @@ var waitReasonStrings = [...]string{
 	waitReasonDebugCall:             "debug call",
 	waitReasonGCMarkTermination:     "GC mark termination",
 	waitReasonStoppingTheWorld:      "stopping the world",
+	waitReasonDeadlockHookIdle:      "deadlock hook (idle)",
+	waitReasonGCPauseHookIdle:       "GC pause hook (idle)",
 }
 
 func (w waitReason) String() string {
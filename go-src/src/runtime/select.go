@@ -173,7 +173,7 @@ func selectgo(cas0 *scase, order0 *uint16, pc0 *uintptr, nsends, nrecvs int, blo
 			continue
 		}
 
-		j := fastrandn(uint32(norder + 1))
+		j := schedrandn(uint32(norder + 1))
 		pollorder[norder] = pollorder[j]
 		pollorder[j] = uint16(i)
 		norder++
@@ -226,18 +226,27 @@ func selectgo(cas0 *scase, order0 *uint16, pc0 *uintptr, nsends, nrecvs int, blo
 		}
 	}
 
+	// The trace context to send with the value, if a send case wins,
+	// and whether it was sent.
+	var sent unsafe.Pointer
+	var sentCarried bool
+	if nsends > 0 {
+		sent = tracingSelectSend(scases[:nsends])
+	}
+
 	// lock all the channels involved in the select
 	sellock(scases, lockorder)
 
 	var (
-		gp     *g
-		sg     *sudog
-		c      *hchan
-		k      *scase
-		sglist *sudog
-		sgnext *sudog
-		qp     unsafe.Pointer
-		nextp  **sudog
+		gp       *g
+		sg       *sudog
+		c        *hchan
+		k        *scase
+		sglist   *sudog
+		sgnext   *sudog
+		qp       unsafe.Pointer
+		nextp    **sudog
+		recvSent unsafe.Pointer
 	)
 
 	// pass 1 - look for something already waiting
@@ -301,6 +310,9 @@ func selectgo(cas0 *scase, order0 *uint16, pc0 *uintptr, nsends, nrecvs int, blo
 		// No stack splits between assigning elem and enqueuing
 		// sg on gp.waiting where copystack can find it.
 		sg.elem = cas.elem
+		if casi < nsends && tracingChanCarries(c) {
+			sg.traceData = sent
+		}
 		sg.releasetime = 0
 		if t0 != 0 {
 			sg.releasetime = -1
@@ -359,6 +371,9 @@ func selectgo(cas0 *scase, order0 *uint16, pc0 *uintptr, nsends, nrecvs int, blo
 			if sglist.releasetime > 0 {
 				caseReleaseTime = sglist.releasetime
 			}
+			if int(casei) >= nsends {
+				recvSent = sglist.traceData
+			}
 		} else {
 			c = k.c
 			if int(casei) < nsends {
@@ -369,6 +384,7 @@ func selectgo(cas0 *scase, order0 *uint16, pc0 *uintptr, nsends, nrecvs int, blo
 		}
 		sgnext = sglist.waitlink
 		sglist.waitlink = nil
+		sglist.traceData = nil
 		releaseSudog(sglist)
 		sglist = sgnext
 	}
@@ -387,6 +403,7 @@ func selectgo(cas0 *scase, order0 *uint16, pc0 *uintptr, nsends, nrecvs int, blo
 		if !caseSuccess {
 			goto sclose
 		}
+		sentCarried = tracingChanCarries(c)
 	} else {
 		recvOK = caseSuccess
 	}
@@ -436,6 +453,10 @@ bufrecv:
 		typedmemmove(c.elemtype, cas.elem, qp)
 	}
 	typedmemclr(c.elemtype, qp)
+	if c.tracebuf != nil {
+		recvSent = c.tracebuf[c.recvx]
+		c.tracebuf[c.recvx] = nil
+	}
 	c.recvx++
 	if c.recvx == c.dataqsiz {
 		c.recvx = 0
@@ -457,6 +478,10 @@ bufsend:
 		asanread(cas.elem, c.elemtype.size)
 	}
 	typedmemmove(c.elemtype, chanbuf(c, c.sendx), cas.elem)
+	if c.tracebuf != nil {
+		c.tracebuf[c.sendx] = sent
+		sentCarried = true
+	}
 	c.sendx++
 	if c.sendx == c.dataqsiz {
 		c.sendx = 0
@@ -467,7 +492,7 @@ bufsend:
 
 recv:
 	// can receive from sleeping sender (sg)
-	recv(c, sg, cas.elem, func() { selunlock(scases, lockorder) }, 2)
+	recvSent = recv(c, sg, cas.elem, func() { selunlock(scases, lockorder) }, 2)
 	if debugSelect {
 		print("syncrecv: cas0=", cas0, " c=", c, "\n")
 	}
@@ -497,7 +522,8 @@ send:
 	if asanenabled {
 		asanread(cas.elem, c.elemtype.size)
 	}
-	send(c, sg, cas.elem, func() { selunlock(scases, lockorder) }, 2)
+	send(c, sg, cas.elem, sent, func() { selunlock(scases, lockorder) }, 2)
+	sentCarried = true
 	if debugSelect {
 		print("syncsend: cas0=", cas0, " c=", c, "\n")
 	}
@@ -507,11 +533,16 @@ retc:
 	if caseReleaseTime > 0 {
 		blockevent(caseReleaseTime-t0, 1)
 	}
+	if !sentCarried {
+		tracingChanDiscard(sent)
+	}
+	tracingChanRecv(recvSent)
 	return casi, recvOK
 
 sclose:
 	// send on closed channel
 	selunlock(scases, lockorder)
+	tracingChanDiscard(sent)
 	panic(plainError("send on closed channel"))
 }
 
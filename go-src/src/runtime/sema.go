@@ -144,6 +144,9 @@ func semacquire1(addr *uint32, lifo bool, profile semaProfileFlags, skipframes i
 		}
 		s.acquiretime = t0
 	}
+	if profile&semaMutexProfile != 0 {
+		tracingLockContended(addr, reason)
+	}
 	for {
 		lockWithRank(&root.lock, lockRankRoot)
 		// Add ourselves to nwait to disable "easy case" in semrelease.
@@ -165,7 +168,12 @@ func semacquire1(addr *uint32, lifo bool, profile semaProfileFlags, skipframes i
 	if s.releasetime > 0 {
 		blockevent(s.releasetime-t0, 3+skipframes)
 	}
+	holder := s.traceData
+	s.traceData = nil
 	releaseSudog(s)
+	if profile&semaMutexProfile != 0 {
+		tracingLockAcquired(addr, reason, holder)
+	}
 }
 
 func semrelease(addr *uint32) {
@@ -204,6 +212,10 @@ func semrelease1(addr *uint32, handoff bool, skipframes int) {
 		if s.ticket != 0 {
 			throw("corrupted semaphore ticket")
 		}
+		if tracingHooks.lockAcquired != nil {
+			// Tell the waiter who held the lock it was waiting for.
+			s.traceData = getg().gdata[gdataTrace]
+		}
 		if handoff && cansemacquire(addr) {
 			s.ticket = 1
 		}
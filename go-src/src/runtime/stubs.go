@@ -154,6 +154,28 @@ func fastrandn(n uint32) uint32 {
 	return uint32(uint64(fastrand()) * uint64(n) >> 32)
 }
 
+// schedrand is the state of the generator used by schedrandn
+// when GODEBUG=schedseed is set. It is seeded in schedinit.
+var schedrand uint64
+
+// schedrandn returns a pseudo-random number in [0, n) for a scheduling
+// decision. With GODEBUG=schedseed=N and GOMAXPROCS=1, the numbers come
+// from a single generator seeded with N, rather than the per-M fastrand
+// state, so that the program makes the same decisions each run. The
+// generator is not synchronized, so it is only used while there is a
+// single P, when only one M schedules at a time. With more Ps, the
+// scheduler cannot be deterministic anyway, and fastrandn is used.
+//
+//go:nosplit
+func schedrandn(n uint32) uint32 {
+	if debug.schedseed == 0 || gomaxprocs != 1 {
+		return fastrandn(n)
+	}
+	schedrand += 0xa0761d6478bd642f
+	hi, lo := math.Mul64(schedrand, schedrand^0xe7037ed1a0b428db)
+	return uint32(uint64(uint32(hi^lo)) * uint64(n) >> 32)
+}
+
 func fastrand64() uint64 {
 	mp := getg().m
 	// Implement wyrand: https://github.com/wangyi-fudan/wyhash
@@ -0,0 +1,52 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+)
+
+func init() {
+	register("SchedSeed", SchedSeed)
+}
+
+// SchedSeed prints the order in which goroutines run, yield and
+// receive from a select, which depends only on GODEBUG=schedseed when
+// run with GOMAXPROCS=1.
+func SchedSeed() {
+	var mu sync.Mutex
+	var order []byte
+	record := func(b byte) {
+		mu.Lock()
+		order = append(order, b)
+		mu.Unlock()
+	}
+
+	var wg sync.WaitGroup
+	c1, c2 := make(chan byte, 8), make(chan byte, 8)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(b byte) {
+			defer wg.Done()
+			record(b)
+			runtime.Gosched()
+			record(b)
+		}('a' + byte(i))
+		c1 <- '1'
+		c2 <- '2'
+	}
+	wg.Wait()
+	for i := 0; i < 8; i++ {
+		select {
+		case b := <-c1:
+			record(b)
+		case b := <-c2:
+			record(b)
+		}
+	}
+	fmt.Println(string(order))
+}
@@ -0,0 +1,180 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/hooks"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unsafe"
+)
+
+func init() {
+	register("TracingCrashPanic", TracingCrashPanic)
+	register("TracingCrashFatal", TracingCrashFatal)
+	register("TracingDeadlock", TracingDeadlock)
+	register("SchedClass", SchedClass)
+	register("SchedBackgroundBusy", SchedBackgroundBusy)
+	register("TracingDebug", TracingDebug)
+	register("TracingSystemGoroutines", TracingSystemGoroutines)
+}
+
+// registerTracingCrash registers crash and traceback hooks reporting on
+// the trace data of the goroutines at the time of the crash, and
+// attaches trace data to the calling goroutine.
+func registerTracingCrash() {
+	hooks.RegisterCrashHooks(hooks.CrashHooks{
+		Crash: func(goRoutineID uint64, traceData unsafe.Pointer, panicValue any, fatalError string) {
+			n := 0
+			hooks.EachGoroutineTraceData(func(goRoutineID uint64, traceData unsafe.Pointer) {
+				n++
+			})
+			fmt.Fprintf(os.Stderr, "crash hook: data=%d panic=%v fatal=%s\n", n, panicValue, fatalError)
+		},
+	})
+	hooks.RegisterTracebackHooks(hooks.TracebackHooks{
+		Label: func(traceData unsafe.Pointer) string { return "crash-test" },
+	})
+	hooks.SetTraceData(unsafe.Pointer(new(int)))
+}
+
+func TracingCrashPanic() {
+	registerTracingCrash()
+	panic("boom")
+}
+
+func TracingCrashFatal() {
+	registerTracingCrash()
+	var mu sync.Mutex
+	mu.Unlock()
+}
+
+func TracingDeadlock() {
+	registerTracingCrash()
+	select {}
+}
+
+// SchedClass starts goroutines of each scheduling class at once, and
+// checks that the scheduler mostly runs the high class goroutines first
+// and the background ones last. Each class is only a preference, so
+// some rounds may run in another order.
+func SchedClass() {
+	runtime.GOMAXPROCS(1)
+	classes := []hooks.SchedClass{hooks.SchedNormal, hooks.SchedBackground, hooks.SchedHigh, hooks.SchedHigh, hooks.SchedNormal}
+	const rounds = 100
+	inOrder := 0
+	ran := make(chan hooks.SchedClass, len(classes))
+	for i := 0; i < rounds; i++ {
+		for _, c := range classes {
+			hooks.SetSchedClass(c)
+			go func() {
+				ran <- hooks.CurrentSchedClass()
+			}()
+		}
+		hooks.SetSchedClass(hooks.SchedNormal)
+		var order []hooks.SchedClass
+		for range classes {
+			order = append(order, <-ran)
+		}
+		if order[0] == hooks.SchedHigh && order[1] == hooks.SchedHigh && order[len(order)-1] == hooks.SchedBackground {
+			inOrder++
+		}
+	}
+	if inOrder < rounds*3/4 {
+		fmt.Printf("goroutines ran high class first and background last in %d of %d rounds\n", inOrder, rounds)
+		return
+	}
+	fmt.Println("OK")
+}
+
+// SchedBackgroundBusy checks that a background goroutine still runs
+// while normal goroutines keep the P busy, yielding to each other
+// through the global runnable queue.
+func SchedBackgroundBusy() {
+	runtime.GOMAXPROCS(1)
+	var stop atomic.Bool
+	for i := 0; i < 4; i++ {
+		go func() {
+			for !stop.Load() {
+				runtime.Gosched()
+			}
+		}()
+	}
+	done := make(chan struct{})
+	hooks.SetSchedClass(hooks.SchedBackground)
+	go close(done)
+	hooks.SetSchedClass(hooks.SchedNormal)
+	select {
+	case <-done:
+		fmt.Println("OK")
+	case <-time.After(10 * time.Second):
+		fmt.Println("background goroutine did not run")
+	}
+	stop.Store(true)
+}
+
+// TracingDebug starts a goroutine with trace data, which parks and hands
+// the trace data back over a channel, for GODEBUG=tracinghooks to print.
+func TracingDebug() {
+	hooks.RegisterGoroutineHooks(hooks.GoroutineHooks{
+		Start: func(pc uintptr, goRoutineID uint64, parentTraceData unsafe.Pointer) unsafe.Pointer {
+			return parentTraceData
+		},
+	})
+	hooks.RegisterParkHooks(hooks.ParkHooks{
+		Park:  func(uint64, unsafe.Pointer, hooks.WaitReason, int64) {},
+		Ready: func(uint64, unsafe.Pointer, hooks.WaitReason, int64) {},
+	})
+	hooks.RegisterChanHooks(hooks.ChanHooks{
+		Send: func(traceData unsafe.Pointer) unsafe.Pointer { return traceData },
+		Recv: func(traceData, sent unsafe.Pointer) unsafe.Pointer { return sent },
+	})
+	hooks.SetTraceData(unsafe.Pointer(new(int)))
+	done := make(chan struct{})
+	go func() {
+		time.Sleep(time.Millisecond)
+		close(done)
+	}()
+	<-done
+	c := make(chan int)
+	go func() {
+		c <- 1
+	}()
+	hooks.SetTraceData(nil)
+	<-c
+	hooks.SetTraceData(nil)
+
+	// Wait for the goroutines to exit.
+	for {
+		n := 0
+		hooks.EachGoroutineTraceData(func(uint64, unsafe.Pointer) { n++ })
+		if n == 0 {
+			break
+		}
+		runtime.Gosched()
+	}
+}
+
+// TracingSystemGoroutines starts the GC workers with trace data attached,
+// and reports any runtime goroutine the start hook is called for.
+func TracingSystemGoroutines() {
+	hooks.RegisterGoroutineHooks(hooks.GoroutineHooks{
+		Start: func(pc uintptr, goRoutineID uint64, parentTraceData unsafe.Pointer) unsafe.Pointer {
+			if name := runtime.FuncForPC(pc).Name(); strings.HasPrefix(name, "runtime.") {
+				println("start hook called for", name)
+			}
+			return parentTraceData
+		},
+	})
+	hooks.SetTraceData(unsafe.Pointer(new(int)))
+	runtime.GC()
+	hooks.SetTraceData(nil)
+	fmt.Println("OK")
+}
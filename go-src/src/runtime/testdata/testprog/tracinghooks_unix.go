@@ -0,0 +1,40 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build unix
+
+package main
+
+import (
+	"runtime/hooks"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+func init() {
+	register("TracingSigquit", TracingSigquit)
+}
+
+// TracingSigquit starts a goroutine with trace data and a label set by
+// its owner, and exits on SIGQUIT, whose dump can't call the label hook.
+func TracingSigquit() {
+	registerTracingCrash()
+	hooks.RegisterGoroutineHooks(hooks.GoroutineHooks{
+		Start: func(pc uintptr, goRoutineID uint64, parentTraceData unsafe.Pointer) unsafe.Pointer {
+			return parentTraceData
+		},
+	})
+	ready := make(chan struct{})
+	go func() {
+		hooks.SetTracebackLabel("sigquit-test")
+		close(ready)
+		select {}
+	}()
+	<-ready
+	syscall.Kill(syscall.Getpid(), syscall.SIGQUIT)
+
+	// See SignalExitStatus.
+	time.Sleep(time.Second)
+}
@@ -37,6 +37,14 @@ type timer struct {
 
 	// The status field holds one of the values below.
 	status atomic.Uint32
+
+	// goFunc is set by time.AfterFunc, whose f, time.goFunc, starts a
+	// goroutine which inherits traceData.
+	goFunc bool
+
+	// traceData is the trace data of the goroutine which last set the
+	// timer through package time, for goroutines started by f.
+	traceData unsafe.Pointer
 }
 
 // Code outside this file has to be careful in using a timer value.
@@ -212,6 +220,7 @@ func startTimer(t *timer) {
 	if raceenabled {
 		racerelease(unsafe.Pointer(t))
 	}
+	tracingSetTimer(t)
 	addtimer(t)
 }
 
@@ -232,6 +241,7 @@ func resetTimer(t *timer, when int64) bool {
 	if raceenabled {
 		racerelease(unsafe.Pointer(t))
 	}
+	tracingSetTimer(t)
 	return resettimer(t, when)
 }
 
@@ -239,6 +249,7 @@ func resetTimer(t *timer, when int64) bool {
 //
 //go:linkname modTimer time.modTimer
 func modTimer(t *timer, when, period int64, f func(any, uintptr), arg any, seq uintptr) {
+	tracingSetTimer(t)
 	modtimer(t, when, period, f, arg, seq)
 }
 
@@ -850,6 +861,7 @@ func runOneTimer(pp *p, t *timer, now int64) {
 	f := t.f
 	arg := t.arg
 	seq := t.seq
+	traceData := t.traceData
 
 	if t.period > 0 {
 		// Leave in heap but adjust next time to fire.
@@ -871,9 +883,9 @@ func runOneTimer(pp *p, t *timer, now int64) {
 		}
 	}
 
+	gp := getg()
 	if raceenabled {
 		// Temporarily use the current P's racectx for g0.
-		gp := getg()
 		if gp.racectx != 0 {
 			throw("runOneTimer: unexpected racectx")
 		}
@@ -882,12 +894,16 @@ func runOneTimer(pp *p, t *timer, now int64) {
 
 	unlock(&pp.timersLock)
 
+	// Goroutines started by f, such as the one started by the
+	// time.AfterFunc timer function, take the trace data of the
+	// goroutine which set the timer. See gdataInherit.
+	gp.m.timerTraceData = traceData
 	f(arg, seq)
+	gp.m.timerTraceData = nil
 
 	lock(&pp.timersLock)
 
 	if raceenabled {
-		gp := getg()
 		gp.racectx = 0
 	}
 }
@@ -921,6 +921,23 @@ var gStatusStrings = [...]string{
 }
 
 func goroutineheader(gp *g) {
+	var buf [128]byte
+	status := goroutineStatus(buf[:], gp)
+	print("goroutine ", gp.goid, " [", slicebytetostringtmp(&status[0], len(status)), "]")
+	if name := gp.name; name != nil {
+		print(" ")
+		printquoted(name.full)
+	}
+	tracingPrintLabel(gp)
+	print(":\n")
+}
+
+// goroutineStatus formats the status of gp shown in its traceback header,
+// such as "chan receive, 5 minutes, locked to thread", into buf, and
+// returns it, truncated if buf is too short. It is also used to record
+// the state of a goroutine for runtime/hooks, see saveRootGoroutine.
+// It must not allocate, as goroutineheader runs in signal handlers.
+func goroutineStatus(buf []byte, gp *g) []byte {
 	gpstatus := readgstatus(gp)
 
 	isScan := gpstatus&_Gscan != 0
@@ -944,17 +961,20 @@ func goroutineheader(gp *g) {
 	if (gpstatus == _Gwaiting || gpstatus == _Gsyscall) && gp.waitsince != 0 {
 		waitfor = (nanotime() - gp.waitsince) / 60e9
 	}
-	print("goroutine ", gp.goid, " [", status)
+	n := copy(buf, status)
 	if isScan {
-		print(" (scan)")
+		n += copy(buf[n:], " (scan)")
 	}
 	if waitfor >= 1 {
-		print(", ", waitfor, " minutes")
+		var minutes [20]byte
+		n += copy(buf[n:], ", ")
+		n += copy(buf[n:], itoa(minutes[:], uint64(waitfor)))
+		n += copy(buf[n:], " minutes")
 	}
 	if gp.lockedm != 0 {
-		print(", locked to thread")
+		n += copy(buf[n:], ", locked to thread")
 	}
-	print("]:\n")
+	return buf[:n]
 }
 
 func tracebackothers(me *g) {
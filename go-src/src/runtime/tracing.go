@@ -1,35 +1,1215 @@
 package runtime
 
 import (
+	"internal/abi"
+	"runtime/internal/atomic"
+	"runtime/internal/sys"
 	"unsafe"
 )
 
-// tracingGStart is called when a goroutine starts. It returns a pointer to a
-// the parent routines trace data, and is expected to return a pointer to the
-// new routines trace data.
+// hooks_setGoroutineHooks is called by runtime/hooks.RegisterGoroutineHooks.
+// The goroutine hooks own the trace data slot, so start is called as that
+// slot is inherited by a new goroutine and exit as it is cleaned up.
 //
-// If the parent Go routine has no tracing data, this won't get called
-// thus it is always safe to assume parent is non-nil
-func tracingGStart(pc uintptr, goRoutinueID uint64, parentTraceData unsafe.Pointer) unsafe.Pointer
+//go:linkname hooks_setGoroutineHooks runtime/hooks.setGoroutineHooks
+func hooks_setGoroutineHooks(start func(uintptr, uint64, unsafe.Pointer) unsafe.Pointer, exit func(uint64, unsafe.Pointer), rename func(uint64, unsafe.Pointer, string)) {
+	gdataSlots[gdataTrace].inherit = start
+	gdataSlots[gdataTrace].cleanup = exit
+	tracingHooks.rename = rename
+}
+
+// tracingHooks holds the hooks registered through the runtime/hooks package,
+// other than the goroutine start and exit hooks, which live with their data
+// slot. A nil hook means no library has registered one, in which case the
+// runtime skips the call entirely.
+var tracingHooks struct {
+	// rename is called by hooks_setGoroutineName as a goroutine with
+	// trace data sets its name, with its full name.
+	rename func(goRoutineID uint64, traceData unsafe.Pointer, name string)
+
+	// park is called from gopark as a goroutine with trace data is about to
+	// park, and ready as it is made runnable again. Both are passed the
+	// goroutine's wait reason and the current nanotime.
+	park  func(goRoutineID uint64, traceData unsafe.Pointer, reason uint8, now int64)
+	ready func(goRoutineID uint64, traceData unsafe.Pointer, reason uint8, now int64)
+
+	// run is called from execute as a goroutine with trace data starts
+	// running, and stop as it stops running without exiting, either
+	// remaining runnable or not. Both are passed the current nanotime.
+	run  func(goRoutineID uint64, traceData unsafe.Pointer, now int64)
+	stop func(goRoutineID uint64, traceData unsafe.Pointer, runnable bool, now int64)
+
+	// chanSend is called on a goroutine with trace data as it is about to
+	// send on a channel which can carry a context, see tracingChanCarries.
+	// The context it returns travels with the element and is passed to
+	// chanRecv on the receiving goroutine, which returns the receiver's
+	// new trace data. If no element carries it after all, such as when
+	// the channel is closed or a select picks another case, it is passed
+	// to chanDiscard on the sending goroutine instead.
+	chanSend    func(traceData unsafe.Pointer) unsafe.Pointer
+	chanRecv    func(traceData, sent unsafe.Pointer) unsafe.Pointer
+	chanDiscard func(sent unsafe.Pointer)
+
+	// lockContended is called from semacquire1 as a goroutine with trace
+	// data is about to block acquiring a sync.Mutex or sync.RWMutex, and
+	// lockAcquired once it has acquired the semaphore. lockAcquired is
+	// passed the trace data of the goroutine whose release woke it, if any.
+	lockContended func(traceData unsafe.Pointer, lock uintptr, reason uint8, now int64)
+	lockAcquired  func(traceData, holderTraceData unsafe.Pointer, lock uintptr, reason uint8, now int64)
+
+	// panic is called from gopanic as a goroutine with trace data starts
+	// to panic, with the panic value and the goroutine's stack. recover
+	// is called from gorecover as such a goroutine recovers a panic.
+	panic   func(traceData unsafe.Pointer, value any, stack []uintptr)
+	recover func(traceData unsafe.Pointer, value any)
+
+	// crash is called once as the program crashes with an unrecovered
+	// panic or a fatal error, if the crashing goroutine can run it.
+	crash func(goRoutineID uint64, traceData unsafe.Pointer, panicValue any, fatalError string)
+
+	// alloc is called as a goroutine with trace data has a sampled
+	// allocation, on average once every tracingAllocRate bytes, and as
+	// its trace data changes or it exits.
+	alloc func(traceData unsafe.Pointer, bytes, objects uintptr)
+
+	// gcPause is called by tracingGCPauseHelper after each
+	// stop-the-world phase of a garbage collection, with the times the
+	// world stopped and restarted. gcAssist is called as a goroutine
+	// with trace data finishes assisting the garbage collector.
+	gcPause  func(start, end int64)
+	gcAssist func(traceData unsafe.Pointer, start, end int64)
+
+	// syscall is called as a goroutine with trace data returns from a
+	// system call or cgo call, with the times it entered and left it.
+	syscall func(traceData unsafe.Pointer, cgo bool, start, end int64)
+
+	// label returns the label to print in the traceback header of a
+	// goroutine with the given trace data. As most tracebacks can't call
+	// it, it is called as the trace data of a goroutine is set and as the
+	// goroutine is renamed, and the result kept in g.traceLabel.
+	label func(traceData unsafe.Pointer) string
+}
+
+// tracingCreateStackDepth is the maximum number of frames of a traced
+// goroutine's stack recorded on each goroutine it creates.
+var tracingCreateStackDepth int
+
+// tracingAllocRate is the average number of bytes a goroutine allocates
+// between sampled allocations, or 0 if allocations are not being counted.
+var tracingAllocRate uintptr
+
+// tracingCrashed is set once the crash hook has been called.
+var tracingCrashed atomic.Uint32
+
+// hooks_setParkHooks is called by runtime/hooks.RegisterParkHooks.
+//
+//go:linkname hooks_setParkHooks runtime/hooks.setParkHooks
+func hooks_setParkHooks(park, ready func(uint64, unsafe.Pointer, uint8, int64)) {
+	tracingHooks.park = park
+	tracingHooks.ready = ready
+}
+
+// hooks_setSchedHooks is called by runtime/hooks.RegisterSchedHooks.
+//
+//go:linkname hooks_setSchedHooks runtime/hooks.setSchedHooks
+func hooks_setSchedHooks(run func(uint64, unsafe.Pointer, int64), stop func(uint64, unsafe.Pointer, bool, int64)) {
+	tracingHooks.run = run
+	tracingHooks.stop = stop
+}
+
+// hooks_setGCHooks is called by runtime/hooks.RegisterGCHooks.
+//
+//go:linkname hooks_setGCHooks runtime/hooks.setGCHooks
+func hooks_setGCHooks(pause func(int64, int64), assist func(unsafe.Pointer, int64, int64)) {
+	if pause != nil && tracingGCPauses.started.CompareAndSwap(0, 1) {
+		lockInit(&tracingGCPauses.lock, lockRankTracingGCPause)
+		go tracingGCPauseHelper()
+	}
+	if raceenabled {
+		// The helper may have been started by an earlier registration,
+		// so make this one happen before the helper's calls to pause.
+		racereleasemerge(unsafe.Pointer(&tracingGCPauses))
+	}
+	tracingHooks.gcPause = pause
+	tracingHooks.gcAssist = assist
+}
+
+// hooks_setSyscallHooks is called by runtime/hooks.RegisterSyscallHooks.
+//
+//go:linkname hooks_setSyscallHooks runtime/hooks.setSyscallHooks
+func hooks_setSyscallHooks(syscall func(unsafe.Pointer, bool, int64, int64)) {
+	tracingHooks.syscall = syscall
+}
+
+// hooks_setCreationStackDepth is called by
+// runtime/hooks.SetCreationStackDepth.
+//
+//go:linkname hooks_setCreationStackDepth runtime/hooks.setCreationStackDepth
+func hooks_setCreationStackDepth(depth int) {
+	if depth < 0 {
+		depth = 0
+	}
+	tracingCreateStackDepth = depth
+}
+
+// hooks_creationStack is called by runtime/hooks.CreationStack.
+// As the start hook runs, it returns the stack of the new goroutine.
+//
+//go:linkname hooks_creationStack runtime/hooks.creationStack
+func hooks_creationStack() []uintptr {
+	gp := getg()
+	if newg := gp.traceNewg; newg != nil {
+		return newg.createStack
+	}
+	return gp.createStack
+}
+
+// hooks_setAllocHooks is called by runtime/hooks.RegisterAllocHooks.
+//
+//go:linkname hooks_setAllocHooks runtime/hooks.setAllocHooks
+func hooks_setAllocHooks(rate int, alloc func(unsafe.Pointer, uintptr, uintptr)) {
+	if rate <= 0 || alloc == nil {
+		rate = 0
+	}
+	tracingHooks.alloc = alloc
+	tracingAllocRate = uintptr(rate)
+}
+
+// hooks_setChanHooks is called by runtime/hooks.RegisterChanHooks.
+//
+//go:linkname hooks_setChanHooks runtime/hooks.setChanHooks
+func hooks_setChanHooks(send func(unsafe.Pointer) unsafe.Pointer, recv func(unsafe.Pointer, unsafe.Pointer) unsafe.Pointer, discard func(unsafe.Pointer)) {
+	tracingHooks.chanSend = send
+	tracingHooks.chanRecv = recv
+	tracingHooks.chanDiscard = discard
+}
+
+// hooks_setLockHooks is called by runtime/hooks.RegisterLockHooks.
+//
+//go:linkname hooks_setLockHooks runtime/hooks.setLockHooks
+func hooks_setLockHooks(contended func(unsafe.Pointer, uintptr, uint8, int64), acquired func(unsafe.Pointer, unsafe.Pointer, uintptr, uint8, int64)) {
+	tracingHooks.lockContended = contended
+	tracingHooks.lockAcquired = acquired
+}
+
+// hooks_setPanicHooks is called by runtime/hooks.RegisterPanicHooks.
+//
+//go:linkname hooks_setPanicHooks runtime/hooks.setPanicHooks
+func hooks_setPanicHooks(panic func(unsafe.Pointer, any, []uintptr), recover func(unsafe.Pointer, any)) {
+	tracingHooks.panic = panic
+	tracingHooks.recover = recover
+}
+
+// hooks_setCrashHooks is called by runtime/hooks.RegisterCrashHooks.
+//
+//go:linkname hooks_setCrashHooks runtime/hooks.setCrashHooks
+func hooks_setCrashHooks(crash func(uint64, unsafe.Pointer, any, string)) {
+	tracingHooks.crash = crash
+	if crash != nil && tracingDeadlockStarted.CompareAndSwap(0, 1) {
+		go tracingDeadlockHelper()
+	}
+}
+
+// hooks_setTracebackHooks is called by runtime/hooks.RegisterTracebackHooks.
+//
+//go:linkname hooks_setTracebackHooks runtime/hooks.setTracebackHooks
+func hooks_setTracebackHooks(label func(unsafe.Pointer) string) {
+	tracingHooks.label = label
+}
+
+// hooks_eachGoroutineTraceData is called by
+// runtime/hooks.EachGoroutineTraceData.
+//
+//go:linkname hooks_eachGoroutineTraceData runtime/hooks.eachGoroutineTraceData
+func hooks_eachGoroutineTraceData(fn func(uint64, unsafe.Pointer)) {
+	type entry struct {
+		goid      uint64
+		traceData unsafe.Pointer
+	}
+
+	// Take a snapshot first, so fn is free to start goroutines.
+	// The world isn't stopped, so this is racy, but each goroutine's
+	// trace data is a single pointer written only by the goroutine itself.
+	var entries []entry
+	forEachGRace(func(gp *g) {
+		if traceData := gp.gdata[gdataTrace]; traceData != nil && readgstatus(gp) != _Gdead {
+			entries = append(entries, entry{gp.goid, traceData})
+		}
+	})
+	for _, e := range entries {
+		fn(e.goid, e.traceData)
+	}
+}
+
+// hooks_waitReasonString is called by runtime/hooks.WaitReason.String.
+//
+//go:linkname hooks_waitReasonString runtime/hooks.waitReasonString
+func hooks_waitReasonString(reason uint8) string {
+	return waitReason(reason).String()
+}
+
+// tracingPark calls the park hook if gp has trace data.
+// gp must be the current goroutine, which is about to park. It is called
+// by gopark before acquirem, but gopark's caller may still hold the lock
+// it passes to gopark, such as a channel's lock or a semaRoot's lock,
+// which is only released by park_m.
+func tracingPark(gp *g, reason waitReason) {
+	if traceData := gp.gdata[gdataTrace]; traceData != nil && tracingHooks.park != nil {
+		if debug.tracinghooks > 1 {
+			tracingDebug(gp, "park", traceData)
+		}
+		tracingHooks.park(gp.goid, traceData, uint8(reason), nanotime())
+	}
+}
+
+// tracingReady calls the ready hook if gp has trace data.
+// gp must be parked, with its wait reason still set.
+func tracingReady(gp *g) {
+	if traceData := gp.gdata[gdataTrace]; traceData != nil && tracingHooks.ready != nil {
+		if debug.tracinghooks > 1 {
+			tracingDebug(gp, "ready", traceData)
+		}
+		var borrowed bool
+		if raceenabled {
+			var ok bool
+			if borrowed, ok = tracingRaceBeginG(gp); !ok {
+				return
+			}
+		}
+		tracingHooks.ready(gp.goid, traceData, uint8(gp.waitreason), nanotime())
+		if raceenabled {
+			tracingRaceEndG(gp, borrowed)
+		}
+	}
+}
+
+// tracingRun calls the run hook if gp has trace data.
+// It is called on the system stack as gp starts running.
+func tracingRun(gp *g) {
+	if now := gp.traceStopTime; now != 0 {
+		gp.traceStopTime = 0
+		tracingStopAt(gp, true, now)
+	}
+	if traceData := gp.gdata[gdataTrace]; traceData != nil && tracingHooks.run != nil {
+		if debug.tracinghooks > 1 {
+			tracingDebug(gp, "run", traceData)
+		}
+		var borrowed bool
+		if raceenabled {
+			var ok bool
+			if borrowed, ok = tracingRaceBeginG(gp); !ok {
+				return
+			}
+		}
+		tracingHooks.run(gp.goid, traceData, nanotime())
+		if raceenabled {
+			tracingRaceEndG(gp, borrowed)
+		}
+	}
+}
+
+// tracingStop calls the stop hook if gp has trace data. It is called on
+// the system stack as gp stops running, and runnable reports whether gp
+// is going back on a run queue rather than waiting.
+func tracingStop(gp *g, runnable bool) {
+	tracingStopAt(gp, runnable, 0)
+}
+
+// tracingStopSyscall records the time gp stops as exitsyscall0 finds no
+// P for it to run on. Without a P, exitsyscall0 can't run ordinary Go
+// code, so tracingRun calls the stop hook with that time instead, as
+// execute runs gp again.
+//
+//go:nowritebarrierrec
+func tracingStopSyscall(gp *g) {
+	if gp.gdata[gdataTrace] != nil && tracingHooks.stop != nil {
+		gp.traceStopTime = nanotime()
+	}
+}
+
+// tracingStopAt is tracingStop, calling the hook with the time now,
+// or the current time if now is 0.
+func tracingStopAt(gp *g, runnable bool, now int64) {
+	if traceData := gp.gdata[gdataTrace]; traceData != nil && tracingHooks.stop != nil {
+		if debug.tracinghooks > 1 {
+			tracingDebug(gp, "stop", traceData)
+		}
+		var borrowed bool
+		if raceenabled {
+			var ok bool
+			if borrowed, ok = tracingRaceBeginG(gp); !ok {
+				return
+			}
+		}
+		if now == 0 {
+			now = nanotime()
+		}
+		tracingHooks.stop(gp.goid, traceData, runnable, now)
+		if raceenabled {
+			tracingRaceEndG(gp, borrowed)
+		}
+	}
+}
+
+// tracingChanCarries reports whether the elements sent on c can carry a
+// trace context. Unbuffered channels hand it to the receiver with the
+// element, and buffered ones keep it in c.tracebuf, which channels made
+// before the channel hooks were registered lack.
+func tracingChanCarries(c *hchan) bool {
+	return c.dataqsiz == 0 || c.tracebuf != nil
+}
+
+// tracingChanSend calls the channel send hook if the current goroutine has
+// trace data and c can carry it, returning the context to carry with the
+// sent element. If the send fails, or can't carry the context after all,
+// the caller must pass it to tracingChanDiscard.
+// It must be called without any channel locks held.
+func tracingChanSend(c *hchan) unsafe.Pointer {
+	if !tracingChanCarries(c) {
+		return nil
+	}
+	return tracingChanSendHook()
+}
+
+// tracingSelectSend is tracingChanSend for the send cases of a select,
+// calling the hook if the channel of any of them can carry the context.
+func tracingSelectSend(sends []scase) unsafe.Pointer {
+	if getg().gdata[gdataTrace] == nil || tracingHooks.chanSend == nil {
+		return nil
+	}
+	for i := range sends {
+		if c := sends[i].c; c != nil && tracingChanCarries(c) {
+			return tracingChanSendHook()
+		}
+	}
+	return nil
+}
+
+func tracingChanSendHook() unsafe.Pointer {
+	if traceData := getg().gdata[gdataTrace]; traceData != nil && tracingHooks.chanSend != nil {
+		if debug.tracinghooks > 1 {
+			tracingDebug(getg(), "chan send", traceData)
+		}
+		return tracingHooks.chanSend(traceData)
+	}
+	return nil
+}
+
+// tracingChanDiscard calls the channel discard hook with a context
+// returned by tracingChanSend which no element carries.
+// It must be called without any channel locks held.
+func tracingChanDiscard(sent unsafe.Pointer) {
+	if sent != nil && tracingHooks.chanDiscard != nil {
+		if debug.tracinghooks > 1 {
+			tracingDebug(getg(), "chan discard", sent)
+		}
+		tracingHooks.chanDiscard(sent)
+	}
+}
+
+// tracingChanRecv calls the channel receive hook if the element just
+// received carried a context, and installs the result as the current
+// goroutine's trace data.
+// It must be called without any channel locks held.
+func tracingChanRecv(sent unsafe.Pointer) {
+	if sent != nil && tracingHooks.chanRecv != nil {
+		gp := getg()
+		if debug.tracinghooks > 1 {
+			tracingDebug(gp, "chan recv", sent)
+		}
+		tracingSetData(gp, tracingHooks.chanRecv(gp.gdata[gdataTrace], sent))
+	}
+}
+
+// tracingLockContended calls the lock contended hook if the current
+// goroutine has trace data. It must be called without runtime locks held.
+func tracingLockContended(addr *uint32, reason waitReason) {
+	if traceData := getg().gdata[gdataTrace]; traceData != nil && tracingHooks.lockContended != nil {
+		tracingHooks.lockContended(traceData, uintptr(unsafe.Pointer(addr)), uint8(reason), nanotime())
+	}
+}
+
+// tracingLockAcquired calls the lock acquired hook if the current
+// goroutine has trace data. holder is the trace data of the goroutine
+// which woke it. It must be called without runtime locks held.
+func tracingLockAcquired(addr *uint32, reason waitReason, holder unsafe.Pointer) {
+	if traceData := getg().gdata[gdataTrace]; traceData != nil && tracingHooks.lockAcquired != nil {
+		tracingHooks.lockAcquired(traceData, holder, uintptr(unsafe.Pointer(addr)), uint8(reason), nanotime())
+	}
+}
+
+// tracingPanicStackDepth is the maximum number of PCs
+// passed to the panic hook.
+const tracingPanicStackDepth = 64
+
+// tracingPanic calls the panic hook if gp has trace data.
+// gp must be the current goroutine, which is starting to panic with e.
+func tracingPanic(gp *g, e any) {
+	traceData := gp.gdata[gdataTrace]
+	if traceData == nil || tracingHooks.panic == nil {
+		return
+	}
+	if gp.panicHookSP != 0 {
+		// The hook itself panicked. Let the panic proceed without
+		// calling the hook again. panicHookSP stays set until the
+		// hook returns, or its frame is unwound by tracingUnwind.
+		return
+	}
+
+	// Skip tracingPanic and gopanic, so the stack
+	// starts at the function which panicked.
+	stack := make([]uintptr, tracingPanicStackDepth)
+	stack = stack[:callers(2, stack)]
+
+	// Record the frame as an offset, which stays valid as the stack moves.
+	gp.panicHookSP = gp.stack.hi - getcallersp()
+	tracingHooks.panic(traceData, e, stack)
+	gp.panicHookSP = 0
+}
+
+// tracingUnwind is called by recovery as it unwinds gp's stack to the
+// frame at sp. If a panic from the panic hook is being recovered outside
+// the hook, the hook never returns, so the next panic calls it again.
+func tracingUnwind(gp *g, sp uintptr) {
+	if gp.panicHookSP != 0 && gp.stack.hi-sp < gp.panicHookSP {
+		gp.panicHookSP = 0
+	}
+}
+
+// tracingRecover calls the recover hook if the current goroutine has
+// trace data. It is called by gopanic as the goroutine recovers the panic
+// value e, after the deferred call which recovered it has returned, and
+// with the panic off gp._panic, so that if the hook panics, that panic
+// starts afresh.
+func tracingRecover(e any) {
+	if traceData := getg().gdata[gdataTrace]; traceData != nil && tracingHooks.recover != nil {
+		tracingHooks.recover(traceData, e)
+	}
+}
+
+// tracingCreateStack returns the stack to record on a goroutine being
+// created by gp, or nil if gp has no trace data or stacks aren't being
+// recorded. It is called by newproc, and skips newproc's frame.
+func tracingCreateStack(gp *g) []uintptr {
+	depth := tracingCreateStackDepth
+	if depth == 0 || gp.gdata[gdataTrace] == nil {
+		return nil
+	}
+	stack := make([]uintptr, depth)
+	return stack[:callers(2, stack)]
+}
+
+// tracingGCPauses queues the stop-the-world phases of garbage collections
+// for tracingGCPauseHelper to pass to the GC pause hook. The phases end in
+// gcStart, gcMarkDone and gcMarkTermination, which may run within
+// mallocgc as a goroutine assists the collector, so can't call the hook
+// themselves. It is started as a GC pause hook is first registered. If
+// the hook falls behind, further pauses are dropped until it catches up.
+var tracingGCPauses struct {
+	lock    mutex
+	g       *g
+	idle    bool
+	n       int
+	pauses  [16]struct{ start, end int64 }
+	started atomic.Uint32
+}
+
+func tracingGCPauseHelper() {
+	tracingGCPauses.g = getg()
+	var pauses [len(tracingGCPauses.pauses)]struct{ start, end int64 }
+	for {
+		lock(&tracingGCPauses.lock)
+		for tracingGCPauses.n == 0 {
+			tracingGCPauses.idle = true
+			goparkunlock(&tracingGCPauses.lock, waitReasonGCPauseHookIdle, traceEvGoBlock, 1)
+			lock(&tracingGCPauses.lock)
+		}
+		n := copy(pauses[:], tracingGCPauses.pauses[:tracingGCPauses.n])
+		tracingGCPauses.n = 0
+		unlock(&tracingGCPauses.lock)
+
+		if f := tracingHooks.gcPause; f != nil {
+			if raceenabled {
+				raceacquire(unsafe.Pointer(&tracingGCPauses))
+			}
+			for _, p := range pauses[:n] {
+				f(p.start, p.end)
+			}
+		}
+	}
+}
+
+// tracingGCPause queues a stop-the-world phase from start to end for the
+// GC pause hook, waking tracingGCPauseHelper if it is idle. It is called by
+// gcStart, gcMarkDone and gcMarkTermination once they have restarted the
+// world and released it.
+func tracingGCPause(start, end int64) {
+	if tracingHooks.gcPause == nil {
+		return
+	}
+	lock(&tracingGCPauses.lock)
+	if tracingGCPauses.n < len(tracingGCPauses.pauses) {
+		tracingGCPauses.pauses[tracingGCPauses.n] = struct{ start, end int64 }{start, end}
+		tracingGCPauses.n++
+	}
+	var gp *g
+	if tracingGCPauses.idle {
+		tracingGCPauses.idle = false
+		gp = tracingGCPauses.g
+	}
+	unlock(&tracingGCPauses.lock)
+	if gp != nil {
+		goready(gp, 0)
+	}
+}
+
+// tracingGCAssistStart returns the time gp, which has to assist the
+// garbage collector, starts doing so, or 0 if it has no trace data or
+// the GC assist hook isn't registered.
+func tracingGCAssistStart(gp *g) int64 {
+	if tracingHooks.gcAssist == nil || gp.gdata[gdataTrace] == nil {
+		return 0
+	}
+	return nanotime()
+}
+
+// tracingGCAssist calls the GC assist hook as gp finishes the assist it
+// started at start. It is called by deductAssistCredit, before mallocgc
+// starts allocating.
+func tracingGCAssist(gp *g, start int64) {
+	if f := tracingHooks.gcAssist; f != nil {
+		f(gp.gdata[gdataTrace], start, nanotime())
+	}
+}
+
+// tracingEnterSyscall records the time gp is entering a system call or
+// cgo call, if it has trace data and the syscall hook is registered. It
+// is called by entersyscall and entersyscallblock, so must be nosplit.
+//
+//go:nosplit
+func tracingEnterSyscall(gp *g) {
+	if tracingHooks.syscall != nil && gp.traceSyscallStart == 0 && gp.gdata[gdataTrace] != nil {
+		gp.traceSyscallStart = nanotime()
+	}
+}
+
+// tracingExitSyscall calls the syscall hook as gp returns from the system
+// call or cgo call timed by tracingEnterSyscall. It is called at the end
+// of exitsyscall, once gp has a P and is running normally again.
+//
+// The call is a cgo call if cgocall marked it so on its way out, or if it
+// is returning to Go for a callback from C, in which case gp.m is in C.
+func tracingExitSyscall(gp *g) {
+	start, cgo := gp.traceSyscallStart, gp.traceInCgo || gp.m.incgo
+
+	// Don't time any system calls made by the hook itself.
+	gp.traceSyscallStart, gp.traceInCgo = -1, false
+	if f := tracingHooks.syscall; f != nil {
+		f(gp.gdata[gdataTrace], cgo, start, nanotime())
+	}
+	gp.traceSyscallStart = 0
+}
+
+// tracingAlloc counts an allocation of size bytes by the current
+// goroutine, if it has trace data. It is called at the end of mallocgc,
+// with size rounded up to the size class allocated. Like the memory
+// profile, it only samples allocations, on average one every
+// tracingAllocRate bytes, so most calls only count down to the next.
+func tracingAlloc(size uintptr) {
+	gp := getg()
+	if gp.gdata[gdataTrace] == nil {
+		return
+	}
+	if size < gp.traceAllocNext {
+		gp.traceAllocNext -= size
+		return
+	}
+	tracingSampleAlloc(gp, size)
+}
+
+// tracingSampleAlloc samples an allocation of size bytes by gp, which
+// must be the current goroutine, and calls the alloc hook if it is safe
+// to. Otherwise, as when mallocgc is called with runtime locks held, the
+// counts are reported at gp's next sampled allocation, or as its trace
+// data changes or it exits.
+//
+// The bytes gp allocates are counted exactly: traceAllocBytes is credited
+// with each sampling interval as it is drawn, and traceAllocNext is the
+// part of it gp has yet to allocate. The number of objects is estimated
+// from the size of each sampled allocation, as for the memory profile.
+func tracingSampleAlloc(gp *g, size uintptr) {
+	rate := tracingAllocRate
+	if gp.traceAllocBytes == 0 {
+		// gp has not counted down from a sampling interval yet.
+		next := uintptr(fastexprand(int(rate)))
+		gp.traceAllocNext, gp.traceAllocBytes = next, next
+		if size < next {
+			gp.traceAllocNext -= size
+			return
+		}
+	}
+	gp.traceAllocBytes += size - gp.traceAllocNext
+	if size < rate {
+		gp.traceAllocObjects += rate / size
+	} else {
+		gp.traceAllocObjects++
+	}
+	next := uintptr(fastexprand(int(rate)))
+	gp.traceAllocNext = next
+	gp.traceAllocBytes += next
+
+	if mp := gp.m; gp == mp.curg && mp.locks == 0 && mp.preemptoff == "" {
+		tracingFlushAlloc(gp)
+	}
+}
+
+// tracingFlushAlloc reports the allocations counted by tracingAlloc for
+// gp, which must be the current goroutine, to the alloc hook. It is called
+// as allocations are sampled, and before gp's trace data changes or it
+// exits, so allocations are credited to the right data.
+func tracingFlushAlloc(gp *g) {
+	bytes, objects := gp.traceAllocBytes-gp.traceAllocNext, gp.traceAllocObjects
+	if bytes == 0 && objects == 0 {
+		return
+	}
+	// Reset the counts first, as the hook may itself allocate.
+	gp.traceAllocBytes, gp.traceAllocObjects = gp.traceAllocNext, 0
+	if traceData := gp.gdata[gdataTrace]; traceData != nil && tracingHooks.alloc != nil {
+		tracingHooks.alloc(traceData, bytes, objects)
+	}
+}
+
+// tracingCrashStart reports whether the current goroutine is to call the
+// crash hook, as it is about to crash the program. The hook is only
+// called once, and only if tracingCanCrash. If it reports true, the
+// caller prints why the program is crashing and then calls tracingCrash,
+// so the reason is out even if the hook never returns. It is nosplit,
+// like fatal which calls it.
+//
+//go:nosplit
+func tracingCrashStart() bool {
+	return tracingHooks.crash != nil && tracingCanCrash() && tracingCrashed.CompareAndSwap(0, 1)
+}
+
+// tracingCanCrash reports whether the current goroutine, which is about
+// to crash the program, is in a state where it is safe to run ordinary
+// Go code, such as the crash and label hooks.
+//
+//go:nosplit
+func tracingCanCrash() bool {
+	gp := getg()
+	if gp != gp.m.curg || gp.throwsplit {
+		return false
+	}
+	mp := gp.m
+	return mp.locks == 0 && mp.mallocing == 0 && mp.preemptoff == "" && mp.dying == 0
+}
+
+// tracingCrash calls the crash hook after tracingCrashStart, as the
+// current goroutine crashes the program with panicValue or fatalError.
+//
+//go:nosplit
+func tracingCrash(panicValue any, fatalError string) {
+	gp := getg()
+	tracingHooks.crash(gp.goid, gp.gdata[gdataTrace], panicValue, fatalError)
+}
+
+// tracingDeadlockG is the goroutine which calls the crash hook as
+// checkdead finds the program deadlocked, which it can't do itself as it
+// runs on the system stack with the scheduler lock held. It is started
+// as a crash hook is first registered, and parks until tracingDeadlock
+// wakes it.
+var (
+	tracingDeadlockG       guintptr
+	tracingDeadlockStarted atomic.Uint32
+	tracingDeadlockGoexit  bool // whether it crashes with deadlockGoexitError
+	tracingDeadlockPrinted bool
+)
+
+func tracingDeadlockHelper() {
+	tracingDeadlockG.set(getg())
+	gopark(nil, nil, waitReasonDeadlockHookIdle, traceEvGoBlock, 1)
+
+	fatalError := deadlockError
+	if tracingDeadlockGoexit {
+		fatalError = deadlockGoexitError
+	}
+	tracingPrintDeadlocked()
+	// fatal calls the crash hook, with no trace data as we have none.
+	fatal(fatalError)
+}
+
+// tracingDeadlock is called by checkdead, with sched.lock held, as it
+// finds every goroutine blocked and is about to crash with deadlockError,
+// or deadlockGoexitError if goexit is set. If a crash hook is registered,
+// it wakes tracingDeadlockG to list the blocked goroutines with trace
+// data, with their labels, call the hook and crash. It reports whether it
+// did so, in which case checkdead must not crash. Otherwise, it lists the
+// goroutines itself.
+func tracingDeadlock(goexit bool) bool {
+	assertLockHeld(&sched.lock)
+
+	if tracingWakeDeadlockHelper(goexit) {
+		return true
+	}
+	tracingPrintDeadlocked()
+	return false
+}
+
+// tracingPrintDeadlocked lists the goroutines with trace data, all of
+// which are blocked in a deadlock, unless they have been listed already.
+func tracingPrintDeadlocked() {
+	if tracingDeadlockPrinted {
+		return
+	}
+	tracingDeadlockPrinted = true
+	printed := false
+	forEachG(func(gp *g) {
+		if gp.gdata[gdataTrace] == nil || isSystemGoroutine(gp, false) {
+			return
+		}
+		if !printed {
+			print("goroutines with trace data in deadlock:\n")
+			printed = true
+		}
+		print("\tgoroutine ", gp.goid, " [", gp.waitreason.String(), "]")
+		tracingPrintLabel(gp)
+		print("\n")
+	})
+}
+
+// tracingWakeDeadlockHelper wakes tracingDeadlockG, if there is a crash
+// hook for it to call, reporting whether it did so.
+func tracingWakeDeadlockHelper(goexit bool) bool {
+	gp := tracingDeadlockG.ptr()
+	if gp == nil || tracingHooks.crash == nil || tracingCrashed.Load() != 0 || readgstatus(gp) != _Gwaiting {
+		return false
+	}
+
+	// Start an M to run the helper, as checkdead does for timers
+	// when faketime is in use.
+	pp, _ := pidleget(0)
+	if pp == nil {
+		return false
+	}
+	mp := mget()
+	if mp == nil {
+		pidleput(pp, 0)
+		return false
+	}
+	tracingDeadlockGoexit = goexit
+	if trace.enabled {
+		traceGoUnpark(gp, 0)
+	}
+	casgstatus(gp, _Gwaiting, _Grunnable)
+	globrunqput(gp)
+	mp.nextp.set(pp)
+	notewakeup(&mp.park)
+	return true
+}
+
+// tracingSetTimer records the current goroutine's trace data on t, which
+// it is setting through package time, if t was created by time.AfterFunc
+// to start a goroutine. See runOneTimer. Other timers, such as the one
+// sending on the channel of a time.Timer, start no goroutines, so need no
+// trace data.
+func tracingSetTimer(t *timer) {
+	gp := getg()
+	if gp.gdata[gdataTrace] == nil || !t.goFunc {
+		t.traceData = nil
+		return
+	}
+	t.traceData = gp.gdata[gdataTrace]
+}
+
+// tracingRaceBegin prepares to call a hook from the system stack when the
+// race detector is enabled. Hooks are ordinary Go code, so are instrumented
+// by the race detector and need a race context, which the system stack does
+// not have. Like runOneTimer, we borrow the current P's timer race context.
+//
+// It reports whether the race context was borrowed, in which case
+// tracingRaceEnd must be called after the hook, and whether the hook
+// can be called at all, which it can't without a P.
+func tracingRaceBegin() (borrowed, ok bool) {
+	gp := getg()
+	if gp.racectx != 0 {
+		return false, true
+	}
+	pp := gp.m.p.ptr()
+	if pp == nil {
+		return false, false
+	}
+	if pp.timerRaceCtx == 0 {
+		pp.timerRaceCtx = racegostart(abi.FuncPCABIInternal(runtimer) + sys.PCQuantum)
+	}
+	gp.racectx = pp.timerRaceCtx
+	return true, true
+}
+
+// tracingRaceEnd returns the race context borrowed by tracingRaceBegin.
+func tracingRaceEnd() {
+	getg().racectx = 0
+}
+
+// tracingRaceBeginG is like tracingRaceBegin, for a hook called on behalf
+// of gp, which isn't running. It also synchronizes gp's accesses to its
+// trace data so far with the hook's.
+func tracingRaceBeginG(gp *g) (borrowed, ok bool) {
+	if borrowed, ok = tracingRaceBegin(); ok {
+		racereleasemergeg(gp, unsafe.Pointer(&gp.gdata))
+		raceacquire(unsafe.Pointer(&gp.gdata))
+	}
+	return borrowed, ok
+}
+
+// tracingRaceEndG ends a hook begun with tracingRaceBeginG, synchronizing
+// its writes to the trace data with gp's later reads of it, which all
+// acquire &gp.gdata.
+func tracingRaceEndG(gp *g, borrowed bool) {
+	racereleasemerge(unsafe.Pointer(&gp.gdata))
+	if borrowed {
+		tracingRaceEnd()
+	}
+}
+
+// A traceRoot groups the goroutines started on behalf of a single request,
+// as created by runtime/hooks.NewRoot. A goroutine joins a root explicitly,
+// or by being started by a goroutine in the root.
+type traceRoot struct {
+	live atomic.Int64 // number of live goroutines in the root
+}
+
+// rootGoroutineRecord must match runtime/hooks.GoroutineRecord.
+type rootGoroutineRecord struct {
+	id          uint64
+	parentID    uint64
+	createdBy   uintptr
+	createStack []uintptr
+	state       string
+	name        string
+	stack0      [32]uintptr
+}
+
+// hooks_newRoot is called by runtime/hooks.NewRoot.
+//
+//go:linkname hooks_newRoot runtime/hooks.newRoot
+func hooks_newRoot() unsafe.Pointer {
+	return unsafe.Pointer(new(traceRoot))
+}
+
+// hooks_enterRoot is called by runtime/hooks.Root.Enter.
+//
+//go:linkname hooks_enterRoot runtime/hooks.enterRoot
+func hooks_enterRoot(root *traceRoot) {
+	gp := getg()
+	if gp.traceRoot == root {
+		return
+	}
+	if gp.traceRoot != nil {
+		gp.traceRoot.live.Add(-1)
+	}
+	root.live.Add(1)
+	gp.traceRoot = root
+}
 
-// tracingGExit is called when a goroutine exits. It is passed a pointer to the
-// trace data of the exiting goroutine.
+// hooks_leaveRoot is called by runtime/hooks.Root.Leave.
 //
-// If no tracing data is available, this won't get called
-// thus it is always safe to assume parent is non-nil
-func tracingGExit(goRoutinueID uint64, traceData unsafe.Pointer)
+//go:linkname hooks_leaveRoot runtime/hooks.leaveRoot
+func hooks_leaveRoot(root *traceRoot) {
+	gp := getg()
+	if gp.traceRoot == root {
+		root.live.Add(-1)
+		gp.traceRoot = nil
+	}
+}
+
+// hooks_currentRoot is called by runtime/hooks.CurrentRoot.
+//
+//go:linkname hooks_currentRoot runtime/hooks.currentRoot
+func hooks_currentRoot() unsafe.Pointer {
+	return unsafe.Pointer(getg().traceRoot)
+}
+
+// hooks_rootCount is called by runtime/hooks.Root.Count.
+//
+//go:linkname hooks_rootCount runtime/hooks.rootCount
+func hooks_rootCount(root *traceRoot) int {
+	return int(root.live.Load())
+}
+
+// hooks_rootGoroutines is called by runtime/hooks.Root.Goroutines.
+// Like GoroutineProfile, it fills in records and returns the number
+// of goroutines in root, or reports false if records is too small.
+//
+//go:linkname hooks_rootGoroutines runtime/hooks.rootGoroutines
+func hooks_rootGoroutines(root *traceRoot, records []rootGoroutineRecord) (n int, ok bool) {
+	gp := getg()
+	isOK := func(gp1 *g) bool {
+		return gp1 != gp && gp1.traceRoot == root && readgstatus(gp1) != _Gdead
+	}
+
+	stopTheWorld("root goroutines")
+
+	// World is stopped, no locking required.
+	if gp.traceRoot == root {
+		n++
+	}
+	forEachGRace(func(gp1 *g) {
+		if isOK(gp1) {
+			n++
+		}
+	})
+
+	if n <= len(records) {
+		ok = true
+		r := records
+		if gp.traceRoot == root {
+			sp := getcallersp()
+			pc := getcallerpc()
+			systemstack(func() {
+				saveRootGoroutine(pc, sp, gp, &r[0])
+			})
+			r = r[1:]
+		}
+		forEachGRace(func(gp1 *g) {
+			if !isOK(gp1) || len(r) == 0 {
+				return
+			}
+			// As in goroutineProfileWithLabelsSync, take the
+			// traceback on the system stack.
+			systemstack(func() {
+				saveRootGoroutine(^uintptr(0), ^uintptr(0), gp1, &r[0])
+			})
+			r = r[1:]
+		})
+	}
+
+	startTheWorld()
+	return n, ok
+}
+
+// saveRootGoroutine records gp in r, like saveg, with its state
+// as shown by goroutineheader.
+func saveRootGoroutine(pc, sp uintptr, gp *g, r *rootGoroutineRecord) {
+	r.id = gp.goid
+	r.parentID = gp.parentGoid
+	r.createdBy = gp.gopc
+	r.createStack = gp.createStack
+	if name := gp.name; name != nil {
+		r.name = name.full
+	}
+	var buf [128]byte
+	r.state = string(goroutineStatus(buf[:], gp))
+	n := gentraceback(pc, sp, 0, gp, 0, &r.stack0[0], len(r.stack0), nil, nil, 0)
+	if n < len(r.stack0) {
+		r.stack0[n] = 0
+	}
+}
+
+// hooks_setTraceData is called by runtime/hooks.SetTraceData.
+//
+//go:linkname hooks_setTraceData runtime/hooks.setTraceData
+func hooks_setTraceData(traceData unsafe.Pointer) {
+	tracingSetData(getg(), traceData)
+}
+
+// tracingSetData sets the trace data of gp, which must be the current
+// goroutine.
+func tracingSetData(gp *g, traceData unsafe.Pointer) {
+	if debug.tracinghooks > 0 {
+		if traceData != nil {
+			tracingDebug(gp, "attach", traceData)
+		} else if prev := gp.gdata[gdataTrace]; prev != nil {
+			tracingDebug(gp, "detach", prev)
+		}
+	}
+	tracingFlushAlloc(gp)
+	gp.gdata[gdataTrace] = traceData
+	gp.traceFromTimer = false
+	tracingUpdateLabel(gp)
+}
+
+// A gName is the name of a goroutine, set by runtime/hooks.SetGoroutineName
+// and printed in its traceback header. A goroutine shares its creator's
+// gName until it sets its own, which is prefixed by its creator's name.
+// gNames are immutable, so they can be read while the goroutine runs.
+type gName struct {
+	prefix string // name inherited from the goroutine's creator
+	full   string // prefix and the name set, joined by "/"
+}
+
+// hooks_setGoroutineName is called by runtime/hooks.SetGoroutineName.
+// It returns the goroutine's full name. Names are printed quoted in
+// tracebacks, but may not contain quotes or control characters anyway,
+// so that they read the same everywhere else they are shown.
+//
+//go:linkname hooks_setGoroutineName runtime/hooks.setGoroutineName
+func hooks_setGoroutineName(name string) string {
+	for i := 0; i < len(name); i++ {
+		if c := name[i]; c < ' ' || c == 0x7f || c == '"' {
+			panic(plainError("runtime/hooks: goroutine name contains a quote or control character"))
+		}
+	}
+	gp := getg()
+	var prefix string
+	if old := gp.name; old != nil {
+		if gp.nameOwn {
+			prefix = old.prefix
+		} else {
+			prefix = old.full
+		}
+	}
+	full := name
+	switch {
+	case name == "":
+		full = prefix
+	case prefix != "":
+		full = prefix + "/" + name
+	}
+	if full == "" {
+		gp.name = nil
+	} else {
+		gp.name = &gName{prefix: prefix, full: full}
+	}
+	gp.nameOwn = true
+	if traceData := gp.gdata[gdataTrace]; traceData != nil && tracingHooks.rename != nil {
+		tracingHooks.rename(gp.goid, traceData, full)
+	}
+	tracingUpdateLabel(gp)
+	return full
+}
+
+// hooks_goroutineName is called by runtime/hooks.GoroutineName.
+//
+//go:linkname hooks_goroutineName runtime/hooks.goroutineName
+func hooks_goroutineName() string {
+	if name := getg().name; name != nil {
+		return name.full
+	}
+	return ""
+}
+
+// Scheduling classes of goroutines, set by runtime/hooks.SetSchedClass.
+// A high class goroutine is not kicked out of runnext by a lower one,
+// and when it is made runnable but cannot go in runnext, it goes in its
+// P's runqhigh queue, which runs ahead of runq (see runqputhigh).
+// Background goroutines never go in runnext, and wait in their P's
+// runqbg queue, which runs once runq and the global runnable queue are
+// empty (see runqget).
+const (
+	schedClassNormal = iota
+	schedClassHigh
+	schedClassBackground
+)
+
+// hooks_setSchedClass is called by runtime/hooks.SetSchedClass.
+//
+//go:linkname hooks_setSchedClass runtime/hooks.setSchedClass
+func hooks_setSchedClass(class uint8) {
+	getg().schedClass = class
+}
+
+// hooks_schedClass is called by runtime/hooks.CurrentSchedClass.
+//
+//go:linkname hooks_schedClass runtime/hooks.schedClass
+func hooks_schedClass() uint8 {
+	return getg().schedClass
+}
+
+// tracingDebug prints a line describing a hook event on gp with the given
+// trace data, for GODEBUG=tracinghooks.
+func tracingDebug(gp *g, event string, traceData unsafe.Pointer) {
+	print("tracinghooks: goroutine ", gp.goid, " ", event, " data=", traceData, "\n")
+}
+
+// hooks_debugSpan is called by runtime/hooks/internal/span as a HTTP or
+// SQL span starts or ends. With GODEBUG=tracinghooks=2, it prints a line
+// describing the span, including the message returned by msg, if set.
+//
+//go:linkname hooks_debugSpan runtime/hooks/internal/span.debugSpan
+func hooks_debugSpan(event, kind string, msg func() string) {
+	if debug.tracinghooks > 1 {
+		print("tracinghooks: goroutine ", getg().goid, " span ", event, " ", kind)
+		if msg != nil {
+			print(" ", msg())
+		}
+		print("\n")
+	}
+}
 
-// tracingAttachDataToG attaches the given data to the current goroutine.
-func tracingAttachDataToG(data unsafe.Pointer) {
-	getg().traceData = data
+// tracingDebugStart prints a line describing the start of newg, which
+// has inherited trace data from parent, for GODEBUG=tracinghooks.
+// parent is the g0 of a timer's M for a goroutine started by a timer.
+func tracingDebugStart(newg, parent *g) {
+	traceData := newg.gdata[gdataTrace]
+	if traceData == nil {
+		return
+	}
+	print("tracinghooks: goroutine ", newg.goid, " start")
+	if parent == parent.m.g0 {
+		print(" timer")
+	} else {
+		print(" parent=", parent.goid)
+	}
+	print(" pc=", hex(newg.gopc), " fn=", funcname(findfunc(newg.startpc)), " data=", traceData, "\n")
 }
 
-// tracingGetDataFromG returns the tracing data attached to the current goroutine.
-func tracingGetDataFromG() unsafe.Pointer {
-	return getg().traceData
+// A tracingLabel is the traceback label computed for traceData.
+type tracingLabel struct {
+	traceData unsafe.Pointer
+	label     string
 }
 
-// getgoid returns the ID of the current goroutine.
-func getgoid() uint64 {
+// tracingUpdateLabel calls the label hook for the trace data of gp,
+// keeping the label in gp.traceLabel for goroutineheader to print, as it
+// can't call the hook. gp must be the current goroutine, or a new one
+// whose trace data was just set by its creator, which must be running
+// on a user goroutine.
+func tracingUpdateLabel(gp *g) {
+	traceData := gp.gdata[gdataTrace]
+	if traceData == nil || tracingHooks.label == nil {
+		gp.traceLabel = nil
+		return
+	}
+	gp.traceLabel = &tracingLabel{traceData, tracingHooks.label(traceData)}
+}
+
+// hooks_setTracebackLabel is called by runtime/hooks.SetTracebackLabel.
+//
+//go:linkname hooks_setTracebackLabel runtime/hooks.setTracebackLabel
+func hooks_setTracebackLabel(label string) {
+	gp := getg()
+	if traceData := gp.gdata[gdataTrace]; traceData != nil {
+		gp.traceLabel = &tracingLabel{traceData, label}
+	}
+}
+
+// tracingPrintLabel prints the traceback label of gp, if it has one
+// for its current trace data.
+func tracingPrintLabel(gp *g) {
+	if l := gp.traceLabel; l != nil && l.label != "" && l.traceData == gp.gdata[gdataTrace] {
+		print(" ")
+		printquoted(l.label)
+	}
+}
+
+// hooks_traceData is called by runtime/hooks.TraceData.
+//
+//go:linkname hooks_traceData runtime/hooks.traceData
+func hooks_traceData() unsafe.Pointer {
+	return gdataGet(gdataTrace)
+}
+
+// hooks_getgoid is called by runtime/hooks.GoroutineID.
+//
+//go:linkname hooks_getgoid runtime/hooks.getgoid
+func hooks_getgoid() uint64 {
 	return getg().goid
 }
+
+// hooks_getParentGoid is called by runtime/hooks.ParentGoroutineID.
+//
+//go:linkname hooks_getParentGoid runtime/hooks.getParentGoid
+func hooks_getParentGoid() uint64 {
+	return getg().parentGoid
+}
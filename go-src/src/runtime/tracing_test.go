@@ -0,0 +1,95 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package runtime_test
+
+import (
+	"internal/testenv"
+	"strings"
+	"testing"
+)
+
+func TestTracingCrashHooks(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		want []string
+	}{
+		{"TracingCrashPanic", []string{
+			"panic: boom\ncrash hook: data=1 panic=boom fatal=\n",
+		}},
+		{"TracingCrashFatal", []string{
+			"fatal error: sync: unlock of unlocked mutex\ncrash hook: data=1 panic=<nil> fatal=sync: unlock of unlocked mutex\n",
+			"\ngoroutine 1 [running] \"crash-test\":\n",
+		}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			output := runTestProg(t, "testprog", tt.name)
+			for _, want := range tt.want {
+				if !strings.Contains(output, want) {
+					t.Errorf("output does not contain %q:\n%s", want, output)
+				}
+			}
+		})
+	}
+}
+
+func TestTracingDeadlock(t *testing.T) {
+	// External linking brings in cgo, causing deadlock detection not working.
+	testenv.MustInternalLink(t)
+
+	output := runTestProg(t, "testprog", "TracingDeadlock")
+	for _, want := range []string{
+		"fatal error: all goroutines are asleep - deadlock!\ncrash hook: data=1 panic=<nil> fatal=all goroutines are asleep - deadlock!\n",
+		"goroutines with trace data in deadlock:\n\tgoroutine ",
+		" [select (no cases)] \"crash-test\"\n",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output does not contain %q:\n%s", want, output)
+		}
+	}
+}
+
+func TestTracingSystemGoroutines(t *testing.T) {
+	output := runTestProg(t, "testprog", "TracingSystemGoroutines")
+	if want := "OK\n"; output != want {
+		t.Fatalf("output:\n%s\n\nwanted:\n%s", output, want)
+	}
+}
+
+func TestSchedClass(t *testing.T) {
+	output := runTestProg(t, "testprog", "SchedClass")
+	if want := "OK\n"; output != want {
+		t.Fatalf("output:\n%s\n\nwanted:\n%s", output, want)
+	}
+}
+
+func TestSchedBackgroundBusy(t *testing.T) {
+	output := runTestProg(t, "testprog", "SchedBackgroundBusy")
+	if want := "OK\n"; output != want {
+		t.Fatalf("output:\n%s\n\nwanted:\n%s", output, want)
+	}
+}
+
+func TestTracingDebug(t *testing.T) {
+	for _, tt := range []struct {
+		level string
+		want  []string
+		not   []string
+	}{
+		{"1", []string{"tracinghooks: goroutine ", " attach data=0x", " start parent=", " fn=main.TracingDebug.func", " exit data=0x", " detach data=0x"}, []string{" park data=", " chan recv data="}},
+		{"2", []string{" start parent=", " park data=0x", " ready data=0x", " chan send data=0x", " chan recv data=0x"}, nil},
+	} {
+		output := runTestProg(t, "testprog", "TracingDebug", "GODEBUG=tracinghooks="+tt.level)
+		for _, want := range tt.want {
+			if !strings.Contains(output, want) {
+				t.Errorf("tracinghooks=%s: output does not contain %q:\n%s", tt.level, want, output)
+			}
+		}
+		for _, not := range tt.not {
+			if strings.Contains(output, not) {
+				t.Errorf("tracinghooks=%s: output contains %q:\n%s", tt.level, not, output)
+			}
+		}
+	}
+}
@@ -0,0 +1,31 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build unix
+
+package runtime_test
+
+import (
+	"runtime"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestTracingSigquit(t *testing.T) {
+	if runtime.Sigisblocked(int(syscall.SIGQUIT)) {
+		t.Skip("skipping; SIGQUIT is blocked, see golang.org/issue/19196")
+	}
+
+	output := runTestProg(t, "testprog", "TracingSigquit")
+	for _, want := range []string{
+		"SIGQUIT: quit",
+		" [select (no cases)] \"sigquit-test\":\n",
+		"] \"crash-test\":\n",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output does not contain %q:\n%s", want, output)
+		}
+	}
+}
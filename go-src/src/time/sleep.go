@@ -4,6 +4,8 @@
 
 package time
 
+import "unsafe"
+
 // Sleep pauses the current goroutine for at least the duration d.
 // A negative or zero duration causes Sleep to return immediately.
 func Sleep(d Duration)
@@ -11,14 +13,16 @@ func Sleep(d Duration)
 // Interface to timers implemented in package runtime.
 // Must be in sync with ../runtime/time.go:/^type timer
 type runtimeTimer struct {
-	pp       uintptr
-	when     int64
-	period   int64
-	f        func(any, uintptr) // NOTE: must not be closure
-	arg      any
-	seq      uintptr
-	nextwhen int64
-	status   uint32
+	pp        uintptr
+	when      int64
+	period    int64
+	f         func(any, uintptr) // NOTE: must not be closure
+	arg       any
+	seq       uintptr
+	nextwhen  int64
+	status    uint32
+	goFunc    bool // set by AfterFunc, see goFunc
+	traceData unsafe.Pointer
 }
 
 // when is a helper function for setting the 'when' field of a runtimeTimer.
@@ -163,9 +167,10 @@ func After(d Duration) <-chan Time {
 func AfterFunc(d Duration, f func()) *Timer {
 	t := &Timer{
 		r: runtimeTimer{
-			when: when(d),
-			f:    goFunc,
-			arg:  f,
+			when:   when(d),
+			f:      goFunc,
+			arg:    f,
+			goFunc: true,
 		},
 	}
 	startTimer(&t.r)